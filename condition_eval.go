@@ -0,0 +1,200 @@
+package dodumap
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Characteristics are the values that "CS>100" like conditions check.
+type Characteristics struct {
+	Strength       int `json:"strength"`
+	Intelligence   int `json:"intelligence"`
+	Vitality       int `json:"vitality"`
+	Agility        int `json:"agility"`
+	Chance         int `json:"chance"`
+	Wisdom         int `json:"wisdom"`
+	ActionPoints   int `json:"action_points"`
+	MovementPoints int `json:"movement_points"`
+}
+
+// CharacterProfile is what EvaluateCondition checks conditions against.
+type CharacterProfile struct {
+	Level          int             `json:"level"`
+	Base           Characteristics `json:"base"`  // checked by lower case codes like "Cs"
+	Bonus          Characteristics `json:"bonus"` // added to Base for upper case codes like "CS"
+	Kamas          int             `json:"kamas"`
+	Subscribed     bool            `json:"subscribed"`
+	AlignmentLevel int             `json:"alignment_level"`
+	MountId        int             `json:"mount_id"`        // 0 when no mount is equipped
+	MountFamilyId  int             `json:"mount_family_id"` // family of MountId, like dragoturkeys
+	AreaId         int             `json:"area_id"`
+	SetBonusCount  int             `json:"set_bonus_count"` // equipped items that count for set bonuses
+}
+
+// ConditionResult is the outcome of a condition. Conditions that can not be checked are unknown.
+type ConditionResult int
+
+const (
+	ConditionUnknown ConditionResult = iota
+	ConditionFailed
+	ConditionPassed
+)
+
+func (r ConditionResult) String() string {
+	switch r {
+	case ConditionFailed:
+		return "failed"
+	case ConditionPassed:
+		return "passed"
+	}
+	return "unknown"
+}
+
+// ConditionEvaluation is the result of a condition tree with the conditions that decided it.
+type ConditionEvaluation struct {
+	Result  ConditionResult             `json:"result"`
+	Failed  []*MappedMultilangCondition `json:"failed"`  // failed conditions in branches that did not pass
+	Unknown []*MappedMultilangCondition `json:"unknown"` // conditions that could not be checked in branches that did not pass
+}
+
+// EvaluateCondition checks the tree from ParseCondition or ParseConditionUnity against profile. And and or
+// follow three-valued logic, so an unknown condition only matters when the known ones do not decide.
+// No tree means no conditions and passes.
+func EvaluateCondition(tree *ConditionTreeNodeMapped, profile CharacterProfile) ConditionEvaluation {
+	if tree == nil {
+		return ConditionEvaluation{Result: ConditionPassed}
+	}
+
+	if tree.IsOperand {
+		result := evaluateAtomicCondition(tree.Value, profile)
+		evaluation := ConditionEvaluation{Result: result}
+		switch result {
+		case ConditionFailed:
+			evaluation.Failed = []*MappedMultilangCondition{tree.Value}
+		case ConditionUnknown:
+			evaluation.Unknown = []*MappedMultilangCondition{tree.Value}
+		}
+		return evaluation
+	}
+
+	isAnd := tree.Relation != nil && *tree.Relation == "and"
+	var evaluation ConditionEvaluation
+	if isAnd {
+		evaluation.Result = ConditionPassed
+	} else {
+		evaluation.Result = ConditionFailed
+	}
+	for _, child := range tree.Children {
+		childEvaluation := EvaluateCondition(child, profile)
+		evaluation.Failed = append(evaluation.Failed, childEvaluation.Failed...)
+		evaluation.Unknown = append(evaluation.Unknown, childEvaluation.Unknown...)
+
+		switch {
+		case isAnd && childEvaluation.Result == ConditionFailed:
+			evaluation.Result = ConditionFailed
+		case isAnd && childEvaluation.Result == ConditionUnknown && evaluation.Result == ConditionPassed:
+			evaluation.Result = ConditionUnknown
+		case !isAnd && childEvaluation.Result == ConditionPassed:
+			evaluation.Result = ConditionPassed
+		case !isAnd && childEvaluation.Result == ConditionUnknown && evaluation.Result == ConditionFailed:
+			evaluation.Result = ConditionUnknown
+		}
+	}
+	if tree.Relation == nil {
+		evaluation.Result = ConditionUnknown
+	}
+
+	if evaluation.Result == ConditionPassed {
+		return ConditionEvaluation{Result: ConditionPassed}
+	}
+	return evaluation
+}
+
+// evaluateAtomicCondition checks a single condition with a code that ElementFromCode or ElementFromCodeUnity knows.
+func evaluateAtomicCondition(condition *MappedMultilangCondition, profile CharacterProfile) ConditionResult {
+	if condition == nil || condition.Raw || len(condition.Element) != 2 {
+		return ConditionUnknown
+	}
+
+	characteristics := profile.Base
+	if unicode.IsUpper(rune(condition.Element[1])) {
+		characteristics.Strength += profile.Bonus.Strength
+		characteristics.Intelligence += profile.Bonus.Intelligence
+		characteristics.Vitality += profile.Bonus.Vitality
+		characteristics.Agility += profile.Bonus.Agility
+		characteristics.Chance += profile.Bonus.Chance
+		characteristics.Wisdom += profile.Bonus.Wisdom
+		characteristics.ActionPoints += profile.Bonus.ActionPoints
+		characteristics.MovementPoints += profile.Bonus.MovementPoints
+	}
+
+	var actual int
+	switch strings.ToLower(condition.Element) {
+	case "cs":
+		actual = characteristics.Strength
+	case "ci":
+		actual = characteristics.Intelligence
+	case "cv":
+		actual = characteristics.Vitality
+	case "ca":
+		actual = characteristics.Agility
+	case "cc":
+		actual = characteristics.Chance
+	case "cw":
+		actual = characteristics.Wisdom
+	case "cm":
+		actual = characteristics.MovementPoints
+	case "cp":
+		actual = characteristics.ActionPoints
+	case "pk":
+		if condition.Element == "PK" {
+			actual = profile.Kamas
+		} else {
+			actual = profile.SetBonusCount
+		}
+	case "pl":
+		actual = profile.Level
+	case "pa":
+		actual = profile.AlignmentLevel
+	case "ps":
+		if condition.Element != "Ps" {
+			return ConditionUnknown // gender
+		}
+		if profile.MountId != 0 {
+			actual = 1
+		}
+	case "pz":
+		if profile.Subscribed {
+			actual = 1
+		}
+	case "po":
+		if condition.Element == "PO" {
+			return ConditionUnknown // item in inventory
+		}
+		actual = profile.AreaId
+	case "pf":
+		actual = profile.MountId
+	case "of":
+		actual = profile.MountFamilyId
+	default:
+		return ConditionUnknown
+	}
+
+	var passed bool
+	switch condition.Operator {
+	case "<":
+		passed = actual < condition.Value
+	case ">":
+		passed = actual > condition.Value
+	case "=":
+		passed = actual == condition.Value
+	case "!":
+		passed = actual != condition.Value
+	default:
+		return ConditionUnknown
+	}
+	if passed {
+		return ConditionPassed
+	}
+	return ConditionFailed
+}
@@ -0,0 +1,216 @@
+package dodumap
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// ConditionFormat is the output format of RenderCondition.
+type ConditionFormat int
+
+const (
+	ConditionFormatText ConditionFormat = iota
+	ConditionFormatMarkdown
+	ConditionFormatHTML
+)
+
+var conditionRelationWords = map[string]map[string]string{
+	"and": {"fr": "et", "en": "and", "de": "und", "es": "y", "it": "e", "pt": "e"},
+	"or":  {"fr": "ou", "en": "or", "de": "oder", "es": "o", "it": "o", "pt": "ou"},
+}
+
+var conditionNotWords = map[string]string{"fr": "non", "en": "not", "de": "nicht", "es": "no", "it": "non", "pt": "não"}
+
+var conditionLevelWords = map[string]string{"fr": "Niveau", "en": "Level", "de": "Stufe", "es": "Nivel", "it": "Livello", "pt": "Nível"}
+
+// conditionQuestWords say what is checked about the quest %1, for "=" and for "!".
+var conditionQuestWords = map[ConditionKind]map[string]map[string]string{
+	ConditionKindQuestCompleted: {
+		"=": {"fr": "Quête %1 terminée", "en": "Quest %1 completed", "de": "Quest %1 abgeschlossen", "es": "Misión %1 terminada", "it": "Missione %1 completata", "pt": "Missão %1 concluída"},
+		"!": {"fr": "Quête %1 non terminée", "en": "Quest %1 not completed", "de": "Quest %1 nicht abgeschlossen", "es": "Misión %1 no terminada", "it": "Missione %1 non completata", "pt": "Missão %1 não concluída"},
+	},
+	ConditionKindQuestActive: {
+		"=": {"fr": "Quête %1 en cours", "en": "Quest %1 in progress", "de": "Quest %1 aktiv", "es": "Misión %1 en curso", "it": "Missione %1 in corso", "pt": "Missão %1 em andamento"},
+		"!": {"fr": "Quête %1 pas en cours", "en": "Quest %1 not in progress", "de": "Quest %1 nicht aktiv", "es": "Misión %1 no en curso", "it": "Missione %1 non in corso", "pt": "Missão %1 não em andamento"},
+	},
+	ConditionKindQuestStartable: {
+		"=": {"fr": "Quête %1 disponible", "en": "Quest %1 can be started", "de": "Quest %1 verfügbar", "es": "Misión %1 disponible", "it": "Missione %1 disponibile", "pt": "Missão %1 disponível"},
+		"!": {"fr": "Quête %1 indisponible", "en": "Quest %1 can not be started", "de": "Quest %1 nicht verfügbar", "es": "Misión %1 no disponible", "it": "Missione %1 non disponibile", "pt": "Missão %1 indisponível"},
+	},
+}
+
+// conditionPositiveWords are for "=" conditions whose game texts are negated, like "Different area to: %1".
+var conditionPositiveWords = map[ConditionKind]map[string]string{
+	ConditionKindArea:  {"fr": "Être dans la zone %1", "en": "Be in the area %1", "de": "Im Gebiet %1 sein", "es": "Estar en la zona %1", "it": "Essere nella zona %1", "pt": "Estar na área %1"},
+	ConditionKindMount: {"fr": "Avoir la monture %1 équipée", "en": "Have the mount %1 equipped", "de": "Das Reittier %1 ausgerüstet haben", "es": "Tener la montura %1 equipada", "it": "Avere la cavalcatura %1 equipaggiata", "pt": "Ter a montaria %1 equipada"},
+}
+
+var conditionOperatorSymbols = map[string]string{"<": "<", ">": ">", "=": "=", "!": "≠"}
+
+// conditionRenderer knows how a format writes the parts of a condition.
+type conditionRenderer struct {
+	lang     string
+	escape   func(text string) string
+	code     func(code string) string // raw criteria
+	relation func(word string) string
+}
+
+func newConditionRenderer(lang string, format ConditionFormat) conditionRenderer {
+	switch format {
+	case ConditionFormatMarkdown:
+		escaper := strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`)
+		return conditionRenderer{
+			lang:     lang,
+			escape:   escaper.Replace,
+			code:     func(code string) string { return "`" + code + "`" },
+			relation: func(word string) string { return "_" + word + "_" },
+		}
+	case ConditionFormatHTML:
+		return conditionRenderer{
+			lang:     lang,
+			escape:   html.EscapeString,
+			code:     func(code string) string { return "<code>" + html.EscapeString(code) + "</code>" },
+			relation: func(word string) string { return "<em>" + html.EscapeString(word) + "</em>" },
+		}
+	}
+	identity := func(text string) string { return text }
+	return conditionRenderer{lang: lang, escape: identity, code: identity, relation: identity}
+}
+
+// RenderCondition writes a condition tree as text in lang, like "Level ≥ 50 and (Strength > 200 or
+// Agility > 200)". Nested relations that differ from their parent get parentheses. No tree is an empty text.
+func RenderCondition(tree *ConditionTreeNodeMapped, lang string, format ConditionFormat) string {
+	if tree == nil {
+		return ""
+	}
+	var sb strings.Builder
+	newConditionRenderer(lang, format).node(&sb, tree, "")
+	return sb.String()
+}
+
+// RenderConditionMultilang renders the tree in every language of langs, usually Languages or LanguagesUnity.
+func RenderConditionMultilang(tree *ConditionTreeNodeMapped, langs []string, format ConditionFormat) map[string]string {
+	rendered := make(map[string]string, len(langs))
+	for _, lang := range langs {
+		rendered[lang] = RenderCondition(tree, lang, format)
+	}
+	return rendered
+}
+
+func (r conditionRenderer) node(sb *strings.Builder, node *ConditionTreeNodeMapped, parentRelation string) {
+	if node.IsOperand {
+		sb.WriteString(r.operand(node.Value))
+		return
+	}
+
+	relation := ""
+	if node.Relation != nil {
+		relation = *node.Relation
+	}
+	parenthesized := parentRelation != "" && parentRelation != relation
+	if parenthesized {
+		sb.WriteString("(")
+	}
+	for i, child := range node.Children {
+		if i > 0 {
+			sb.WriteString(" " + r.relation(r.word(conditionRelationWords[relation], relation)) + " ")
+		}
+		r.node(sb, child, relation)
+	}
+	if parenthesized {
+		sb.WriteString(")")
+	}
+}
+
+// word returns the translation of lang, falling back to english and then to fallback.
+func (r conditionRenderer) word(translations map[string]string, fallback string) string {
+	if word, found := translations[r.lang]; found {
+		return word
+	}
+	if word, found := translations["en"]; found {
+		return word
+	}
+	return fallback
+}
+
+func (r conditionRenderer) operand(condition *MappedMultilangCondition) string {
+	if condition == nil {
+		return ""
+	}
+	if condition.Raw {
+		return r.code(condition.Criterion)
+	}
+
+	name, found := condition.Templated[r.lang]
+	if !found {
+		name = condition.Element
+	}
+	symbol, found := conditionOperatorSymbols[condition.Operator]
+	if !found {
+		symbol = condition.Operator
+	}
+
+	switch condition.Kind {
+	case ConditionKindUnknown, ConditionKindCharacteristic, ConditionKindKamas, ConditionKindSetBonus:
+		return r.escape(fmt.Sprintf("%s %s %d", name, symbol, condition.Value))
+	case ConditionKindLevel:
+		symbol, value := inclusiveLevel(condition.Operator, symbol, condition.Value)
+		return r.escape(fmt.Sprintf("%s %s %d", r.word(conditionLevelWords, "Level"), symbol, value))
+	case ConditionKindAlignmentLevel:
+		symbol, value := inclusiveLevel(condition.Operator, symbol, condition.Value)
+		return r.escape(fmt.Sprintf("%s %s %d", name, symbol, value))
+	case ConditionKindJobLevel:
+		// the subject is the job, the level is the last value of "PJ>2,40"
+		if job, found := condition.Subject[r.lang]; found {
+			name = job
+		}
+		symbol, value := inclusiveLevel(condition.Operator, symbol, condition.Value)
+		return r.escape(fmt.Sprintf("%s %s %s %d", name, r.word(conditionLevelWords, "Level"), symbol, value))
+	case ConditionKindQuestCompleted, ConditionKindQuestActive, ConditionKindQuestStartable:
+		quest, found := condition.Subject[r.lang]
+		words, known := conditionQuestWords[condition.Kind][condition.Operator]
+		if found && known {
+			return r.escape(strings.ReplaceAll(r.word(words, "%1"), "%1", quest))
+		}
+		if condition.Operator != "!" {
+			return r.escape(name)
+		}
+	case ConditionKindMountEquipped:
+		return r.escape(name) // templating already picked the text with or without mount
+	case ConditionKindSubscribed:
+		if (condition.Operator == "=") == (condition.Value != 0) {
+			return r.escape(name)
+		}
+	case ConditionKindArea, ConditionKindMount:
+		// the game texts are already negated like "Different area to: Amakna", "=" has its own words
+		if condition.Operator == "!" {
+			return r.escape(name)
+		}
+		subject, found := condition.Subject[r.lang]
+		if !found {
+			subject = fmt.Sprint(condition.Value)
+		}
+		return r.escape(strings.ReplaceAll(r.word(conditionPositiveWords[condition.Kind], "%1"), "%1", subject))
+	default:
+		if condition.Operator == "<" || condition.Operator == ">" {
+			return r.escape(fmt.Sprintf("%s %s %d", name, symbol, condition.Value))
+		}
+		if condition.Operator != "!" {
+			return r.escape(name)
+		}
+	}
+	return r.escape(r.word(conditionNotWords, "not") + " " + name)
+}
+
+// inclusiveLevel rewrites "PL>49" to "≥ 50" since the game texts say "level 50 or higher", so it reads
+// better inclusive.
+func inclusiveLevel(operator string, symbol string, value int) (string, int) {
+	switch operator {
+	case ">":
+		return "≥", value + 1
+	case "<":
+		return "≤", value - 1
+	}
+	return symbol, value
+}
@@ -0,0 +1,358 @@
+package dodumap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConditionKind is what a condition checks, independent of the code that is used for it.
+type ConditionKind int
+
+const (
+	ConditionKindUnknown ConditionKind = iota
+	ConditionKindCharacteristic
+	ConditionKindLevel
+	ConditionKindKamas
+	ConditionKindSetBonus
+	ConditionKindAlignmentLevel
+	ConditionKindMountEquipped
+	ConditionKindSubscribed
+	ConditionKindArea
+	ConditionKindSubArea
+	ConditionKindMap
+	ConditionKindMount
+	ConditionKindMountFamily
+	ConditionKindQuestCompleted
+	ConditionKindQuestActive
+	ConditionKindQuestStartable
+	ConditionKindBreed
+	ConditionKindGender
+	ConditionKindJobLevel
+	ConditionKindItemInInventory
+	ConditionKindEmote
+	ConditionKindAchievement
+	ConditionKindServerType
+)
+
+var conditionKindNames = []string{"unknown", "characteristic", "level", "kamas", "set_bonus", "alignment_level", "mount_equipped",
+	"subscribed", "area", "subarea", "map", "mount", "mount_family", "quest_completed", "quest_active", "quest_startable", "breed",
+	"gender", "job_level", "item_in_inventory", "emote", "achievement", "server_type"}
+
+func (k ConditionKind) String() string {
+	if k < 0 || int(k) >= len(conditionKindNames) {
+		return conditionKindNames[ConditionKindUnknown]
+	}
+	return conditionKindNames[k]
+}
+
+func (k ConditionKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *ConditionKind) UnmarshalText(text []byte) error {
+	*k = ConditionKind(indexOfName(conditionKindNames, string(text)))
+	return nil
+}
+
+// ConditionValueKind is the meaning of a condition value, like the id of a quest or a plain amount.
+type ConditionValueKind int
+
+const (
+	ConditionValueUnknown ConditionValueKind = iota
+	ConditionValueAmount
+	ConditionValueLevel
+	ConditionValueBool // 1 is true
+	ConditionValueQuestId
+	ConditionValueBreedId
+	ConditionValueJobId
+	ConditionValueItemId
+	ConditionValueAreaId
+	ConditionValueSubAreaId
+	ConditionValueMapId
+	ConditionValueMountId
+	ConditionValueMountFamilyId
+	ConditionValueEmoteId
+	ConditionValueAchievementId
+	ConditionValueGender // 0 male, 1 female
+	ConditionValueServerType
+)
+
+var conditionValueKindNames = []string{"unknown", "amount", "level", "bool", "quest_id", "breed_id", "job_id", "item_id", "area_id",
+	"subarea_id", "map_id", "mount_id", "mount_family_id", "emote_id", "achievement_id", "gender", "server_type"}
+
+func (k ConditionValueKind) String() string {
+	if k < 0 || int(k) >= len(conditionValueKindNames) {
+		return conditionValueKindNames[ConditionValueUnknown]
+	}
+	return conditionValueKindNames[k]
+}
+
+func (k ConditionValueKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *ConditionValueKind) UnmarshalText(text []byte) error {
+	*k = ConditionValueKind(indexOfName(conditionValueKindNames, string(text)))
+	return nil
+}
+
+// indexOfName returns the index of name in names, or 0 for the unknown value.
+func indexOfName(names []string, name string) int {
+	for i, known := range names {
+		if known == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// criterion describes a condition code. Codes with game texts get them through dialect.elementFromCode,
+// where %1 or {0} is replaced by the subject of the first value, or the value itself when there is no
+// subject, and %2 or {1} by the second value. Codes without a game text use the template of their kind in
+// conditionKindTemplates. Codes with neither a game text nor a subject are not templated.
+type criterion struct {
+	kind        ConditionKind
+	values      []ConditionValueKind // meaning of the comma separated values
+	subject     func(data GameData, lang string, id int) string
+	negatedCode string // code of the game text for false bool conditions like "Ps=0", when the game has one
+}
+
+// criteria is the catalogue of condition codes. Codes are looked up exactly first, so "PO" and "Po" can
+// differ, and then in lower case like the game text codes always were.
+var criteria = map[string]criterion{
+	"cs": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"ci": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"cv": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"ca": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"cc": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"cw": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"cm": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"cp": {kind: ConditionKindCharacteristic, values: []ConditionValueKind{ConditionValueAmount}},
+	"PK": {kind: ConditionKindKamas, values: []ConditionValueKind{ConditionValueAmount}},
+	"pk": {kind: ConditionKindSetBonus, values: []ConditionValueKind{ConditionValueAmount}},
+	"pl": {
+		kind:   ConditionKindLevel,
+		values: []ConditionValueKind{ConditionValueLevel},
+		subject: func(data GameData, lang string, level int) string {
+			return fmt.Sprint(level + 1) // "PL>49" is shown as "level 50 or higher"
+		},
+	},
+	"pa": {kind: ConditionKindAlignmentLevel, values: []ConditionValueKind{ConditionValueLevel}},
+	"pz": {kind: ConditionKindSubscribed, values: []ConditionValueKind{ConditionValueBool}},
+	"po": {
+		kind:   ConditionKindArea,
+		values: []ConditionValueKind{ConditionValueAreaId},
+		subject: func(data GameData, lang string, id int) string {
+			area, _ := data.Area(id)
+			return data.Text(lang, area.NameId)
+		},
+	},
+	"pf": {
+		kind:   ConditionKindMount,
+		values: []ConditionValueKind{ConditionValueMountId},
+		subject: func(data GameData, lang string, id int) string {
+			mount, _ := data.Mount(id)
+			return data.Text(lang, mount.NameId)
+		},
+	},
+	"of": {
+		kind:   ConditionKindMountFamily,
+		values: []ConditionValueKind{ConditionValueMountFamilyId},
+		subject: func(data GameData, lang string, id int) string {
+			family, _ := data.MountFamily(id)
+			return data.Text(lang, family.NameId)
+		},
+	},
+	"Qf": {kind: ConditionKindQuestCompleted, values: []ConditionValueKind{ConditionValueQuestId}, subject: questName},
+	"Qa": {kind: ConditionKindQuestActive, values: []ConditionValueKind{ConditionValueQuestId}, subject: questName},
+	"Qc": {kind: ConditionKindQuestStartable, values: []ConditionValueKind{ConditionValueQuestId}, subject: questName},
+	"PG": {
+		kind:   ConditionKindBreed,
+		values: []ConditionValueKind{ConditionValueBreedId},
+		subject: func(data GameData, lang string, id int) string {
+			breed, _ := data.Breed(id)
+			return data.Text(lang, breed.ShortNameId)
+		},
+	},
+	"PJ": {
+		kind:   ConditionKindJobLevel,
+		values: []ConditionValueKind{ConditionValueJobId, ConditionValueLevel},
+		subject: func(data GameData, lang string, id int) string {
+			job, _ := data.Job(id)
+			return data.Text(lang, job.NameId)
+		},
+	},
+	"PS": {kind: ConditionKindGender, values: []ConditionValueKind{ConditionValueGender}},
+	"Ps": {
+		kind:        ConditionKindMountEquipped,
+		values:      []ConditionValueKind{ConditionValueBool},
+		negatedCode: "Ps!",
+	},
+	"PO": {
+		kind:   ConditionKindItemInInventory,
+		values: []ConditionValueKind{ConditionValueItemId},
+		subject: func(data GameData, lang string, id int) string {
+			item, _ := data.Item(id)
+			return data.Text(lang, item.NameId)
+		},
+	},
+	"PB": {
+		kind:   ConditionKindSubArea,
+		values: []ConditionValueKind{ConditionValueSubAreaId},
+		subject: func(data GameData, lang string, id int) string {
+			subArea, _ := data.SubArea(id)
+			return data.Text(lang, subArea.NameId)
+		},
+	},
+	"Pm": {kind: ConditionKindMap, values: []ConditionValueKind{ConditionValueMapId}},
+	"PE": {
+		kind:   ConditionKindEmote,
+		values: []ConditionValueKind{ConditionValueEmoteId},
+		subject: func(data GameData, lang string, id int) string {
+			emote, _ := data.Emote(id)
+			return data.Text(lang, emote.NameId)
+		},
+	},
+	"OA": {
+		kind:   ConditionKindAchievement,
+		values: []ConditionValueKind{ConditionValueAchievementId},
+		subject: func(data GameData, lang string, id int) string {
+			achievement, _ := data.Achievement(id)
+			return data.Text(lang, achievement.NameId)
+		},
+	},
+	"ST": {kind: ConditionKindServerType, values: []ConditionValueKind{ConditionValueServerType}},
+}
+
+// criterionAliases are other spellings of a code in criteria.
+var criterionAliases = map[string]string{"Pj": "PJ"}
+
+func lookupCriterion(code string) (criterion, bool) {
+	if aliased, found := criterionAliases[code]; found {
+		code = aliased
+	}
+	if known, found := criteria[code]; found {
+		return known, true
+	}
+	known, found := criteria[strings.ToLower(code)]
+	return known, found
+}
+
+func questName(data GameData, lang string, id int) string {
+	quest, _ := data.Quest(id)
+	return data.Text(lang, quest.NameId)
+}
+
+// templated replaces the placeholders of template with the subject of the first value and the second value.
+// Second values that are levels get the operator, since no template says "or higher" for them.
+func (c criterion) templated(template string, data GameData, lang string, values []int, operator string) string {
+	if len(values) == 0 {
+		return template
+	}
+	var subject string
+	if c.subject != nil {
+		subject = c.subject(data, lang, values[0])
+	}
+	if subject == "" && c.values[0] != ConditionValueBool {
+		subject = fmt.Sprint(values[0])
+	}
+	template = strings.NewReplacer("%1", subject, "{0}", subject).Replace(template)
+	if subject == "" {
+		template = strings.Join(strings.Fields(template), " ") // bool texts like "Equipped %1 mount" have no subject
+	}
+	if len(values) > 1 {
+		second := fmt.Sprint(values[1])
+		if c.values[1] == ConditionValueLevel && operator != "=" {
+			symbol, level := inclusiveLevel(operator, conditionOperatorSymbols[operator], values[1])
+			second = fmt.Sprintf("%s %d", symbol, level) // "PJ>2,40" is "level ≥ 41" like the renderer says
+		}
+		template = strings.NewReplacer("%2", second, "{1}", second).Replace(template)
+	}
+	return template
+}
+
+// parseValues parses the comma separated values of a condition. ok is false when they are no numbers or
+// not as many as the criterion has.
+func (c criterion) parseValues(rawValues string) ([]int, bool) {
+	split := strings.Split(rawValues, ",")
+	if len(split) != len(c.values) {
+		return nil, false
+	}
+	values := make([]int, len(split))
+	for i, rawValue := range split {
+		value, err := strconv.Atoi(rawValue)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+// conditionKindElementIds are the element ids of the kinds whose conditions have no game text. They are
+// far above the ids the element registry hands out, so they never get in its way and stay the same in
+// every run.
+var conditionKindElementIds = map[ConditionKind]int{
+	ConditionKindQuestCompleted:  900001,
+	ConditionKindQuestActive:     900002,
+	ConditionKindQuestStartable:  900003,
+	ConditionKindBreed:           900004,
+	ConditionKindJobLevel:        900005,
+	ConditionKindItemInInventory: 900006,
+	ConditionKindSubArea:         900007,
+	ConditionKindEmote:           900008,
+	ConditionKindAchievement:     900009,
+}
+
+// conditionKindTemplates are the texts of the kinds whose conditions have no game text, with the
+// placeholders of criterion.templated.
+var conditionKindTemplates = map[ConditionKind]map[string]string{
+	ConditionKindQuestCompleted:  {"fr": "Avoir terminé la quête %1", "en": "Have completed the quest %1", "de": "Die Quest %1 abgeschlossen haben", "es": "Haber terminado la misión %1", "it": "Aver completato la missione %1", "pt": "Ter concluído a missão %1"},
+	ConditionKindQuestActive:     {"fr": "Avoir la quête %1 en cours", "en": "Have the quest %1 in progress", "de": "Die Quest %1 aktiv haben", "es": "Tener la misión %1 en curso", "it": "Avere la missione %1 in corso", "pt": "Ter a missão %1 em andamento"},
+	ConditionKindQuestStartable:  {"fr": "Pouvoir commencer la quête %1", "en": "Be able to start the quest %1", "de": "Die Quest %1 beginnen können", "es": "Poder empezar la misión %1", "it": "Poter iniziare la missione %1", "pt": "Poder iniciar a missão %1"},
+	ConditionKindBreed:           {"fr": "Classe : %1", "en": "Class: %1", "de": "Klasse: %1", "es": "Clase: %1", "it": "Classe: %1", "pt": "Classe: %1"},
+	ConditionKindJobLevel:        {"fr": "%1 niveau %2", "en": "%1 level %2", "de": "%1 Stufe %2", "es": "%1 nivel %2", "it": "%1 livello %2", "pt": "%1 nível %2"},
+	ConditionKindItemInInventory: {"fr": "Posséder %1", "en": "Have %1", "de": "%1 besitzen", "es": "Tener %1", "it": "Possedere %1", "pt": "Possuir %1"},
+	ConditionKindSubArea:         {"fr": "Être dans la sous-zone %1", "en": "Be in the subarea %1", "de": "Im Teilgebiet %1 sein", "es": "Estar en la subzona %1", "it": "Essere nella sottozona %1", "pt": "Estar na subárea %1"},
+	ConditionKindEmote:           {"fr": "Connaître l'émote %1", "en": "Know the emote %1", "de": "Das Emote %1 kennen", "es": "Conocer el emote %1", "it": "Conoscere l'emote %1", "pt": "Conhecer o emote %1"},
+	ConditionKindAchievement:     {"fr": "Avoir débloqué le succès %1", "en": "Have unlocked the achievement %1", "de": "Den Erfolg %1 freigeschaltet haben", "es": "Haber desbloqueado el logro %1", "it": "Aver sbloccato il successo %1", "pt": "Ter desbloqueado a conquista %1"},
+}
+
+// subjectConditionWithOperator maps conditions of criteria without game texts, like "Qf=123", with the
+// template of their kind and the name of what they refer to. Their element id is the fixed one of their
+// kind, the element registry only gets game texts. It returns false when there is nothing to name or the
+// referenced entity is unknown.
+func subjectConditionWithOperator(code string, rawValues string, operator string, known criterion, data GameData, out *MappedMultilangCondition) (bool, error) {
+	templates, found := conditionKindTemplates[known.kind]
+	if known.subject == nil || !found {
+		return false, nil
+	}
+	values, ok := known.parseValues(rawValues)
+	if !ok {
+		return false, nil
+	}
+
+	out.Subject = make(map[string]string)
+	for _, lang := range data.Languages() {
+		subject := known.subject(data, lang, values[0])
+		if subject == "" {
+			return false, nil
+		}
+		out.Subject[lang] = subject
+		template, found := templates[lang]
+		if !found {
+			template = templates["en"]
+		}
+		out.Templated[lang] = known.templated(template, data, lang, values, operator)
+	}
+	out.Element = code
+	out.ElementId = conditionKindElementIds[known.kind]
+	out.Value = values[len(values)-1]
+	if len(values) > 1 {
+		out.Parameters = values
+	}
+	out.Operator = operator
+
+	return true, nil
+}
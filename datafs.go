@@ -0,0 +1,157 @@
+package dodumap
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// OpenArchive opens a .zip, .tar.gz or .tgz game dump as fs.FS that can be passed to the *FS loaders.
+// When the files are inside a top level directory of the archive, use fs.Sub to point to it.
+// The returned io.Closer must be closed when the data was parsed.
+func OpenArchive(name string) (fs.FS, io.Closer, error) {
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		reader, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return reader, reader, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer file.Close()
+		fsys, err := TarGzFS(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return fsys, io.NopCloser(nil), nil
+	}
+	return nil, nil, fmt.Errorf("unsupported archive %s, expected .zip, .tar.gz or .tgz", name)
+}
+
+// TarGzFS reads all regular files of a gzip compressed tar stream into memory.
+func TarGzFS(r io.Reader) (fs.FS, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(memFS)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = &memFile{name: path.Base(name), data: content, modTime: header.ModTime}
+	}
+	return files, nil
+}
+
+// OverlayFS looks up files in its layers in order, so earlier layers shadow later ones.
+// It is used to patch single files of a game dump, e.g. with an fstest.MapFS in front of os.DirFS.
+type OverlayFS []fs.FS
+
+func (o OverlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, layer := range o {
+		file, err := layer.Open(name)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// memFS is a flat read only file system for extracted archives. Directories can not be opened.
+type memFS map[string]*memFile
+
+type memFile struct {
+	name    string
+	data    []byte
+	modTime time.Time
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	file, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openMemFile{memFile: file, reader: bytes.NewReader(file.data)}, nil
+}
+
+func (m memFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	file, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(file.data), nil
+}
+
+func (m memFS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+	if dir == "." {
+		return m, nil
+	}
+	sub := make(memFS)
+	prefix := dir + "/"
+	for name, file := range m {
+		if strings.HasPrefix(name, prefix) {
+			sub[strings.TrimPrefix(name, prefix)] = file
+		}
+	}
+	return sub, nil
+}
+
+type openMemFile struct {
+	*memFile
+	reader *bytes.Reader
+}
+
+func (f *openMemFile) Stat() (fs.FileInfo, error) { return f.memFile, nil }
+func (f *openMemFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *openMemFile) Close() error               { return nil }
+
+func (f *memFile) Name() string       { return f.name }
+func (f *memFile) Size() int64        { return int64(len(f.data)) }
+func (f *memFile) Mode() fs.FileMode  { return 0444 }
+func (f *memFile) ModTime() time.Time { return f.modTime }
+func (f *memFile) IsDir() bool        { return false }
+func (f *memFile) Sys() any           { return nil }
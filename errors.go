@@ -0,0 +1,115 @@
+package dodumap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+var (
+	// ErrMissingReference is wrapped by a DataError when an entity points to
+	// another entity that is not part of the loaded game data.
+	ErrMissingReference = errors.New("missing reference")
+
+	// ErrNilInput is returned by the mappers when the game data or the
+	// language dictionaries have not been loaded.
+	ErrNilInput = errors.New("game data or languages are nil")
+
+	// ErrRegistryNotLoaded is returned when persisted ids are needed but were
+	// never loaded.
+	ErrRegistryNotLoaded = errors.New("persisted ids are not loaded")
+
+	// ErrQuestCycle is returned when quests require each other to be
+	// completed first.
+	ErrQuestCycle = errors.New("quests require each other")
+
+	// ErrRecipeCycle is returned when an item is needed to craft itself.
+	ErrRecipeCycle = errors.New("recipe needs its own result")
+)
+
+// DataError describes a problem with a single game data file or with one entity
+// inside of it. Functions returning it still return everything they could parse
+// or map, so callers can decide if a broken entity is fatal for them.
+type DataError struct {
+	File     string // source file relative to the data directory, e.g. "items.json"
+	EntityId int    // -1 when the error is not bound to a single entity
+	Field    string // json field that caused the error, empty when unknown
+	Err      error
+}
+
+func (e *DataError) Error() string {
+	msg := e.File
+	if e.EntityId != -1 {
+		msg += fmt.Sprintf(" id %d", e.EntityId)
+	}
+	if e.Field != "" {
+		msg += fmt.Sprintf(" field %s", e.Field)
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Err)
+}
+
+func (e *DataError) Unwrap() error {
+	return e.Err
+}
+
+func newDataError(file string, entityId int, field string, err error) *DataError {
+	return &DataError{
+		File:     file,
+		EntityId: entityId,
+		Field:    field,
+		Err:      err,
+	}
+}
+
+// decodeError wraps a json decoding error, taking the failing field from it when possible.
+func decodeError(file string, entityId int, err error) *DataError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return newDataError(file, entityId, typeErr.Field, err)
+	}
+	return newDataError(file, entityId, "", err)
+}
+
+// rawEntityId extracts the id of an entity that could not be decoded into its type.
+func rawEntityId(raw []byte) int {
+	var ids struct {
+		Id       *int `json:"id"`
+		ResultId *int `json:"resultId"`
+	}
+	if json.Unmarshal(raw, &ids) != nil {
+		return -1
+	}
+	if ids.Id != nil {
+		return *ids.Id
+	}
+	if ids.ResultId != nil {
+		return *ids.ResultId
+	}
+	return -1
+}
+
+// optionalRawDataFiles were added to the game data later. Older dumps without them still load, the data
+// of missing ones is just empty.
+var optionalRawDataFiles = []string{"spell_levels.json", "monsters.json", "monster_races.json", "jobs.json", "skills.json",
+	"super_areas.json", "sub_areas.json", "emotes.json", "achievements.json"}
+
+// missingOptionalFile reports if err is only that an optional file does not exist.
+func missingOptionalFile(err error) bool {
+	var dataErr *DataError
+	return errors.As(err, &dataErr) && slices.Contains(optionalRawDataFiles, dataErr.File) && errors.Is(dataErr.Err, fs.ErrNotExist)
+}
+
+// collectErrors reads count errors from errs, closes it and joins the non-nil ones. Missing optional
+// files are no error.
+func collectErrors(errs chan error, count int) error {
+	var all []error
+	for range count {
+		if err := <-errs; err != nil && !missingOptionalFile(err) {
+			all = append(all, err)
+		}
+	}
+	close(errs)
+	return errors.Join(all...)
+}
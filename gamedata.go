@@ -0,0 +1,303 @@
+package dodumap
+
+import (
+	"iter"
+	"regexp"
+)
+
+// GameData is the format independent view on a game dump and its translations that the mappers work on.
+// The Dofus 2 json types are used as common representation. NewGameData and NewGameDataUnity adapt the
+// raw data of both game versions, so fixes in the mapping only have to be done once.
+type GameData interface {
+	// Languages returns the language codes that have translations.
+	Languages() []string
+	// Text returns the translation of textId, or an empty string when there is none.
+	Text(lang string, textId int) string
+	// LookupText is Text with a found flag.
+	LookupText(lang string, textId int) (string, bool)
+
+	Item(id int) (JSONGameItem, bool)
+	AllItems() iter.Seq2[int, JSONGameItem]
+	ItemType(id int) (JSONGameItemType, bool)
+	Set(id int) (JSONGameSet, bool)
+	AllSets() iter.Seq2[int, JSONGameSet]
+	Effect(id int) (JSONGameEffect, bool)
+	AllRecipes() iter.Seq2[int, JSONGameRecipe]
+	Spell(id int) (JSONGameSpell, bool)
+	AllSpells() iter.Seq2[int, JSONGameSpell]
+	SpellType(id int) (JSONGameSpellType, bool)
+	SpellLevel(id int) (JSONGameSpellLevel, bool)
+	Title(id int) (JSONGameTitle, bool)
+	AllTitles() iter.Seq2[int, JSONGameTitle]
+	Area(id int) (JSONGameArea, bool)
+	AllAreas() iter.Seq2[int, JSONGameArea]
+	SuperArea(id int) (JSONGameSuperArea, bool)
+	SubArea(id int) (JSONGameSubArea, bool)
+	AllSubAreas() iter.Seq2[int, JSONGameSubArea]
+	Emote(id int) (JSONGameEmote, bool)
+	Achievement(id int) (JSONGameAchievement, bool)
+	Breed(id int) (JSONGameBreed, bool)
+	AllBreeds() iter.Seq2[int, JSONGameBreed]
+	Mount(id int) (JSONGameMount, bool)
+	AllMounts() iter.Seq2[int, JSONGameMount]
+	MountFamily(id int) (JSONGameMountFamily, bool)
+	Quest(id int) (JSONGameQuest, bool)
+	AllQuests() iter.Seq2[int, JSONGameQuest]
+	QuestStep(id int) (JSONGameQuestStep, bool)
+	QuestObjective(id int) (JSONGameQuestObjective, bool)
+	QuestStepRewards(id int) (JSONGameQuestStepRewards, bool)
+	QuestCategory(id int) (JSONGameQuestCategory, bool)
+	AllAlmanaxCalendars() iter.Seq2[int, JSONGameAlamanaxCalendar]
+	Job(id int) (JSONGameJob, bool)
+	AllJobs() iter.Seq2[int, JSONGameJob]
+	AllSkills() iter.Seq2[int, JSONGameSkill]
+	NPC(id int) (JSONGameNPC, bool)
+	AllMonsters() iter.Seq2[int, JSONGameMonster]
+	MonsterRace(id int) (JSONGameMonsterRace, bool)
+	AllNPCs() iter.Seq2[int, JSONGameNPC]
+
+	dialect() *dialect
+}
+
+// dialect holds what differs between the Dofus 2 and Dofus 3 texts and data files.
+type dialect struct {
+	spellIdThreshold    int    // dice values above are spell ids
+	singularEffectNames bool   // effect names are singularized before they get an element id
+	criteriaField       string // json field of the item criteria, used in errors
+	prepareRangeRegex   func(input string, extract bool) (string, *regexp.Regexp)
+	parseSigness        func(input string) (bool, bool)
+	singularPlural      func(input string, amount int, lang string) string
+	deleteDamage        func(input string) string
+	elementFromCode     func(code string) []int // text ids of a condition code, nil if unknown
+	questCriteria       bool                    // single criteria like "Qf=123" are kept, see withQuestCriteria
+	unknownCriteria     bool                    // unknown criteria are kept as raw conditions, see withUnknownCriteria
+}
+
+var dofus2Dialect = dialect{
+	spellIdThreshold:    12000,
+	singularEffectNames: false,
+	criteriaField:       "criteria",
+	prepareRangeRegex:   PrepareAndCreateRangeRegex,
+	parseSigness:        ParseSigness,
+	singularPlural:      SingularPluralFormatter,
+	deleteDamage:        DeleteDamageFormatter,
+	elementFromCode: func(code string) []int {
+		textId := ElementFromCode(code)
+		if textId == -1 {
+			return nil
+		}
+		return []int{textId}
+	},
+}
+
+// dialectData is GameData with a changed copy of its dialect.
+type dialectData struct {
+	GameData
+	changedDialect dialect
+}
+
+func withDialect(data GameData, change func(d *dialect)) GameData {
+	changedDialect := *data.dialect()
+	change(&changedDialect)
+	return &dialectData{GameData: data, changedDialect: changedDialect}
+}
+
+func (d *dialectData) dialect() *dialect {
+	return &d.changedDialect
+}
+
+// withQuestCriteria keeps criteria that consist of a single "=" condition. Item criteria like that are
+// skipped, but quest start criteria are often just "Qf=123".
+func withQuestCriteria(data GameData) GameData {
+	return withDialect(data, func(d *dialect) { d.questCriteria = true })
+}
+
+// withUnknownCriteria keeps criteria without a mapping as raw conditions instead of removing them, so
+// "a|unknown" does not turn into "a".
+func withUnknownCriteria(data GameData) GameData {
+	return withDialect(data, func(d *dialect) { d.unknownCriteria = true })
+}
+
+// dofus2GameData adapts JSONGameData without copying it.
+type dofus2GameData struct {
+	data  *JSONGameData
+	langs map[string]LangDict
+}
+
+// NewGameData adapts Dofus 2 data and translations to GameData.
+func NewGameData(data *JSONGameData, langs map[string]LangDict) GameData {
+	return &dofus2GameData{data: data, langs: langs}
+}
+
+func (d *dofus2GameData) dialect() *dialect {
+	return &dofus2Dialect
+}
+
+func (d *dofus2GameData) Languages() []string {
+	return Languages
+}
+
+func (d *dofus2GameData) Text(lang string, textId int) string {
+	return d.langs[lang].Texts[textId]
+}
+
+func (d *dofus2GameData) LookupText(lang string, textId int) (string, bool) {
+	text, ok := d.langs[lang].Texts[textId]
+	return text, ok
+}
+
+func (d *dofus2GameData) Item(id int) (JSONGameItem, bool) {
+	return d.data.Item(id)
+}
+
+func (d *dofus2GameData) ItemType(id int) (JSONGameItemType, bool) {
+	return d.data.ItemType(id)
+}
+
+func (d *dofus2GameData) Set(id int) (JSONGameSet, bool) {
+	return d.data.Set(id)
+}
+
+func (d *dofus2GameData) Effect(id int) (JSONGameEffect, bool) {
+	return d.data.Effect(id)
+}
+
+func (d *dofus2GameData) Spell(id int) (JSONGameSpell, bool) {
+	return d.data.Spell(id)
+}
+
+func (d *dofus2GameData) SpellType(id int) (JSONGameSpellType, bool) {
+	return d.data.SpellType(id)
+}
+
+func (d *dofus2GameData) SpellLevel(id int) (JSONGameSpellLevel, bool) {
+	return d.data.SpellLevel(id)
+}
+
+func (d *dofus2GameData) Title(id int) (JSONGameTitle, bool) {
+	return d.data.Title(id)
+}
+
+func (d *dofus2GameData) Area(id int) (JSONGameArea, bool) {
+	return d.data.Area(id)
+}
+
+func (d *dofus2GameData) SuperArea(id int) (JSONGameSuperArea, bool) {
+	return d.data.SuperArea(id)
+}
+
+func (d *dofus2GameData) SubArea(id int) (JSONGameSubArea, bool) {
+	return d.data.SubArea(id)
+}
+
+func (d *dofus2GameData) Breed(id int) (JSONGameBreed, bool) {
+	return d.data.Breed(id)
+}
+
+func (d *dofus2GameData) Mount(id int) (JSONGameMount, bool) {
+	return d.data.Mount(id)
+}
+
+func (d *dofus2GameData) MountFamily(id int) (JSONGameMountFamily, bool) {
+	return d.data.MountFamily(id)
+}
+
+func (d *dofus2GameData) Quest(id int) (JSONGameQuest, bool) {
+	return d.data.Quest(id)
+}
+
+func (d *dofus2GameData) QuestStep(id int) (JSONGameQuestStep, bool) {
+	return d.data.QuestStep(id)
+}
+
+func (d *dofus2GameData) QuestObjective(id int) (JSONGameQuestObjective, bool) {
+	return d.data.QuestObjective(id)
+}
+
+func (d *dofus2GameData) QuestStepRewards(id int) (JSONGameQuestStepRewards, bool) {
+	return d.data.QuestStepRewards(id)
+}
+
+func (d *dofus2GameData) QuestCategory(id int) (JSONGameQuestCategory, bool) {
+	return d.data.QuestCategory(id)
+}
+
+func (d *dofus2GameData) AllItems() iter.Seq2[int, JSONGameItem] {
+	return d.data.AllItems()
+}
+
+func (d *dofus2GameData) AllSets() iter.Seq2[int, JSONGameSet] {
+	return d.data.AllSets()
+}
+
+func (d *dofus2GameData) AllSpells() iter.Seq2[int, JSONGameSpell] {
+	return d.data.AllSpells()
+}
+
+func (d *dofus2GameData) AllRecipes() iter.Seq2[int, JSONGameRecipe] {
+	return d.data.AllRecipes()
+}
+
+func (d *dofus2GameData) AllBreeds() iter.Seq2[int, JSONGameBreed] {
+	return d.data.AllBreeds()
+}
+
+func (d *dofus2GameData) AllAreas() iter.Seq2[int, JSONGameArea] {
+	return d.data.AllAreas()
+}
+
+func (d *dofus2GameData) AllSubAreas() iter.Seq2[int, JSONGameSubArea] {
+	return d.data.AllSubAreas()
+}
+
+func (d *dofus2GameData) Emote(id int) (JSONGameEmote, bool) {
+	return d.data.Emote(id)
+}
+
+func (d *dofus2GameData) Achievement(id int) (JSONGameAchievement, bool) {
+	return d.data.Achievement(id)
+}
+
+func (d *dofus2GameData) AllTitles() iter.Seq2[int, JSONGameTitle] {
+	return d.data.AllTitles()
+}
+
+func (d *dofus2GameData) AllQuests() iter.Seq2[int, JSONGameQuest] {
+	return d.data.AllQuests()
+}
+
+func (d *dofus2GameData) AllMounts() iter.Seq2[int, JSONGameMount] {
+	return d.data.AllMounts()
+}
+
+func (d *dofus2GameData) AllMonsters() iter.Seq2[int, JSONGameMonster] {
+	return d.data.AllMonsters()
+}
+
+func (d *dofus2GameData) MonsterRace(id int) (JSONGameMonsterRace, bool) {
+	return d.data.MonsterRace(id)
+}
+
+func (d *dofus2GameData) Job(id int) (JSONGameJob, bool) {
+	return d.data.Job(id)
+}
+
+func (d *dofus2GameData) AllJobs() iter.Seq2[int, JSONGameJob] {
+	return d.data.AllJobs()
+}
+
+func (d *dofus2GameData) AllSkills() iter.Seq2[int, JSONGameSkill] {
+	return d.data.AllSkills()
+}
+
+func (d *dofus2GameData) NPC(id int) (JSONGameNPC, bool) {
+	return d.data.NPC(id)
+}
+
+func (d *dofus2GameData) AllNPCs() iter.Seq2[int, JSONGameNPC] {
+	return d.data.AllNPCs()
+}
+
+func (d *dofus2GameData) AllAlmanaxCalendars() iter.Seq2[int, JSONGameAlamanaxCalendar] {
+	return d.data.AllAlmanaxCalendars()
+}
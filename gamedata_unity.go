@@ -0,0 +1,596 @@
+package dodumap
+
+import (
+	"iter"
+	"strconv"
+)
+
+var unityDialect = dialect{
+	spellIdThreshold:    8000,
+	singularEffectNames: true,
+	criteriaField:       "criterions",
+	prepareRangeRegex:   PrepareAndCreateRangeRegexUnity,
+	parseSigness:        ParseSignessUnity,
+	singularPlural:      SingularPluralFormatterUnity,
+	deleteDamage:        DeleteDamageFormatterUnity,
+	elementFromCode:     ElementFromCodeUnity,
+}
+
+// unityGameData adapts JSONGameDataUnity by converting single entries to the Dofus 2 types when they are accessed.
+type unityGameData struct {
+	data  *JSONGameDataUnity
+	langs map[string]LangDictUnity
+}
+
+// NewGameDataUnity adapts Dofus 3 data and translations to GameData.
+func NewGameDataUnity(data *JSONGameDataUnity, langs map[string]LangDictUnity) GameData {
+	return &unityGameData{data: data, langs: langs}
+}
+
+func (d *unityGameData) dialect() *dialect {
+	return &unityDialect
+}
+
+func (d *unityGameData) Languages() []string {
+	return LanguagesUnity
+}
+
+func (d *unityGameData) Text(lang string, textId int) string {
+	return d.langs[lang].Texts[textId]
+}
+
+func (d *unityGameData) LookupText(lang string, textId int) (string, bool) {
+	text, ok := d.langs[lang].Texts[textId]
+	return text, ok
+}
+
+// convertedLookup finds id with lookup and converts the result.
+func convertedLookup[T any, U any](lookup func(id int) (T, bool), id int, convert func(T) U) (U, bool) {
+	entry, ok := lookup(id)
+	if !ok {
+		var zero U
+		return zero, false
+	}
+	return convert(entry), true
+}
+
+// convertedAll converts every value of entries.
+func convertedAll[T any, U any](entries iter.Seq2[int, T], convert func(T) U) iter.Seq2[int, U] {
+	return func(yield func(int, U) bool) {
+		for id, entry := range entries {
+			if !yield(id, convert(entry)) {
+				return
+			}
+		}
+	}
+}
+
+// unityBool converts the 0/1 integers that Unity uses for booleans.
+func unityBool(value int) bool {
+	return value != 0
+}
+
+// unityTextId converts a text id that Unity stores as string. Invalid ids become -1.
+func unityTextId(value string) int {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return id
+}
+
+// nilIfEmpty keeps the mapped json at null for empty Unity arrays.
+func nilIfEmpty[T any](values []T) []T {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func convertPossibleEffectsUnity(effects []*JSONGameItemPossibleEffectUnity) []*JSONGameItemPossibleEffect {
+	converted := make([]*JSONGameItemPossibleEffect, len(effects))
+	for i, effect := range effects {
+		if effect == nil {
+			continue // keep the position of Unity null references
+		}
+		converted[i] = &JSONGameItemPossibleEffect{
+			EffectId:      effect.EffectId,
+			MinimumValue:  effect.MinimumValue,
+			MaximumValue:  effect.MaximumValue,
+			Value:         effect.Value,
+			BaseEffectId:  effect.BaseEffectId,
+			EffectElement: effect.EffectElement,
+			Dispellable:   effect.Dispellable,
+			SpellId:       effect.SpellId,
+			Duration:      effect.Duration,
+		}
+	}
+	return converted
+}
+
+func convertItemUnity(item JSONGameItemUnity) JSONGameItem {
+	return JSONGameItem{
+		Id:                     item.Id,
+		TypeId:                 item.TypeId,
+		DescriptionId:          item.DescriptionId,
+		IconId:                 item.IconId,
+		NameId:                 item.NameId,
+		Level:                  item.Level,
+		PossibleEffects:        convertPossibleEffectsUnity(item.PossibleEffects),
+		RecipeIds:              nilIfEmpty(item.RecipeIds.Array),
+		Pods:                   item.Pods,
+		EvolutiveEffectIds:     nilIfEmpty(item.EvolutiveEffectIds.Array),
+		DropMonsterIds:         nilIfEmpty(item.DropMonsterIds.Array),
+		ItemSetId:              item.ItemSetId,
+		Criteria:               item.Criterions,
+		CriticalHitBonus:       item.CriticalHitBonus,
+		TwoHanded:              unityBool(item.TwoHanded),
+		MaxCastPerTurn:         item.MaxCastPerTurn,
+		ApCost:                 item.ApCost,
+		Range:                  item.Range,
+		MinRange:               item.MinRange,
+		CriticalHitProbability: item.CriticalHitProbability,
+	}
+}
+
+func convertItemTypeUnity(itemType JSONGameItemTypeUnity) JSONGameItemType {
+	return JSONGameItemType{
+		Id:          itemType.Id,
+		NameId:      itemType.NameId,
+		SuperTypeId: itemType.SuperTypeId,
+		CategoryId:  itemType.CategoryId,
+	}
+}
+
+func convertSetUnity(set JSONGameSetUnity) JSONGameSet {
+	effects := make([][]*JSONGameItemPossibleEffect, len(set.Effects))
+	for i, combo := range set.Effects {
+		effects[i] = convertPossibleEffectsUnity(combo)
+	}
+	return JSONGameSet{
+		Id:      set.Id,
+		ItemIds: set.ItemIds,
+		NameId:  set.NameId,
+		Effects: effects,
+	}
+}
+
+func convertEffectUnity(effect JSONGameEffectUnity) JSONGameEffect {
+	return JSONGameEffect{
+		Id:                       effect.Id,
+		DescriptionId:            effect.DescriptionId,
+		IconId:                   effect.IconId,
+		Characteristic:           effect.Characteristic,
+		Category:                 effect.Category,
+		UseDice:                  unityBool(effect.UseDice),
+		Active:                   unityBool(effect.Active),
+		TheoreticalDescriptionId: unityTextId(effect.TheoreticalDescriptionId),
+		BonusType:                effect.BonusType,
+		ElementId:                effect.ElementId,
+		UseInFight:               unityBool(effect.UseInFight),
+	}
+}
+
+func convertRecipeUnity(recipe JSONGameRecipeUnity) JSONGameRecipe {
+	return JSONGameRecipe{
+		Id:            recipe.Id,
+		NameId:        unityTextId(recipe.NameId),
+		TypeId:        recipe.TypeId,
+		Level:         recipe.Level,
+		IngredientIds: recipe.IngredientIds.Array,
+		Quantities:    recipe.Quantities.Array,
+		JobId:         recipe.JobId,
+		SkillId:       recipe.SkillId,
+	}
+}
+
+func convertSpellUnity(spell JSONGameSpellUnity) JSONGameSpell {
+	return JSONGameSpell{
+		Id:            spell.Id,
+		NameId:        spell.NameId,
+		DescriptionId: spell.DescriptionId,
+		TypeId:        spell.TypeId,
+		Order:         spell.Order,
+		IconId:        spell.IconId,
+		SpellLevels:   spell.SpellLevels.Array,
+	}
+}
+
+func convertSpellLevelUnity(level JSONGameSpellLevelUnity) JSONGameSpellLevel {
+	return JSONGameSpellLevel{
+		Id:                     level.Id,
+		SpellId:                level.SpellId,
+		Grade:                  level.Grade,
+		ApCost:                 level.ApCost,
+		MinRange:               level.MinRange,
+		Range:                  level.Range,
+		CastInLine:             unityBool(level.CastInLine),
+		CastInDiagonal:         unityBool(level.CastInDiagonal),
+		CastTestLos:            unityBool(level.CastTestLos),
+		CriticalHitProbability: level.CriticalHitProbability,
+		RangeCanBeBoosted:      unityBool(level.RangeCanBeBoosted),
+		MaxCastPerTurn:         level.MaxCastPerTurn,
+		MaxCastPerTarget:       level.MaxCastPerTarget,
+		MinCastInterval:        level.MinCastInterval,
+		InitialCooldown:        level.InitialCooldown,
+		GlobalCooldown:         level.GlobalCooldown,
+		MinPlayerLevel:         level.MinPlayerLevel,
+		Effects:                convertPossibleEffectsUnity(level.Effects),
+		CriticalEffect:         convertPossibleEffectsUnity(level.CriticalEffect),
+	}
+}
+
+func convertTitleUnity(title JSONGameTitleUnity) JSONGameTitle {
+	return JSONGameTitle{
+		Id:           title.Id,
+		NameMaleId:   unityTextId(title.NameMaleId),
+		NameFemaleId: unityTextId(title.NameFemaleId),
+		Visible:      unityBool(title.Visible),
+		CategoryId:   title.CategoryId,
+	}
+}
+
+func convertAreaUnity(area JSONGameAreaUnity) JSONGameArea {
+	return JSONGameArea{
+		Id:              area.Id,
+		NameId:          area.NameId,
+		SuperAreaId:     area.SuperAreaId,
+		ContainHouses:   unityBool(area.ContainHouses),
+		ContainPaddocks: unityBool(area.ContainPaddocks),
+		Bounds:          area.Bounds,
+		WorldmapId:      area.WorldmapId,
+		HasWorldMap:     unityBool(area.HasWorldMap),
+	}
+}
+
+func convertSuperAreaUnity(superArea JSONGameSuperAreaUnity) JSONGameSuperArea {
+	return JSONGameSuperArea{
+		Id:          superArea.Id,
+		NameId:      superArea.NameId,
+		WorldmapId:  superArea.WorldmapId,
+		HasWorldMap: unityBool(superArea.HasWorldMap),
+	}
+}
+
+func convertBreedUnity(breed JSONGameBreedUnity) JSONGameBreed {
+	return JSONGameBreed{
+		Id:                         breed.Id,
+		ShortNameId:                unityTextId(breed.ShortNameId),
+		LongNameId:                 unityTextId(breed.LongNameId),
+		DescriptionId:              breed.DescriptionId,
+		GameplayClassDescriptionId: unityTextId(breed.GameplayClassDescriptionId),
+		GuideItemId:                breed.GuideItemId,
+		BreedSpellsId:              breed.BreedSpellsId.Array,
+		BreedRoles:                 breed.BreedRolesId.Array,
+		Complexity:                 breed.Complexity,
+		SortIndex:                  breed.SortIndex,
+	}
+}
+
+func convertMountUnity(mount JSONGameMountUnity) JSONGameMount {
+	return JSONGameMount{
+		Id:       mount.Id,
+		FamilyId: mount.FamilyId,
+		NameId:   mount.NameId,
+		Effects:  convertPossibleEffectsUnity(mount.Effects),
+	}
+}
+
+func convertMountFamilyUnity(family JSONGameMountFamilyUnity) JSONGameMountFamily {
+	return JSONGameMountFamily{
+		Id:      family.Id,
+		NameId:  family.NameId,
+		HeadUri: family.HeadUri,
+	}
+}
+
+func convertQuestUnity(quest JSONGameQuestUnity) JSONGameQuest {
+	return JSONGameQuest{
+		Id:             quest.Id,
+		NameId:         quest.NameId,
+		StepIds:        quest.StepIds.Array,
+		CategoryId:     quest.CategoryId,
+		RepeatType:     quest.RepeatType,
+		RepeatLimit:    quest.RepeatLimit,
+		IsDungeonQuest: unityBool(quest.IsDungeonQuest),
+		LevelMin:       quest.LevelMin,
+		LevelMax:       quest.LevelMax,
+		Followable:     unityBool(quest.Followable),
+		IsPartyQuest:   unityBool(quest.IsPartyQuest),
+		StartCriterion: quest.StartCriterion,
+	}
+}
+
+func convertQuestStepUnity(step JSONGameQuestStepUnity) JSONGameQuestStep {
+	return JSONGameQuestStep{
+		Id:            step.Id,
+		DescriptionId: step.DescriptionId,
+		DialogId:      step.DialogId,
+		NameId:        step.NameId,
+		OptimalLevel:  step.OptimalLevel,
+		Duration:      step.Duration,
+		ObjectiveIds:  step.ObjectiveIds.Array,
+		RewardsIds:    step.RewardsIds.Array,
+		QuestId:       step.QuestId,
+	}
+}
+
+func convertQuestObjectiveUnity(objective JSONGameQuestObjectiveUnity) JSONGameQuestObjective {
+	return JSONGameQuestObjective{
+		Id:     objective.Id,
+		Coords: objective.Coords,
+		MapId:  objective.MapId,
+		Parameters: JSONGameQuestParameter{
+			DungeonOnly: unityBool(objective.Parameters.DungeonOnly),
+			NumParams:   objective.Parameters.NumParams,
+			Parameter0:  objective.Parameters.Parameter0,
+			Parameter1:  objective.Parameters.Parameter1,
+			Parameter2:  objective.Parameters.Parameter2,
+			Parameter3:  objective.Parameters.Parameter3,
+			Parameter4:  objective.Parameters.Parameter4,
+		},
+		StepId: objective.StepId,
+		TypeId: objective.TypeId,
+	}
+}
+
+func convertQuestStepRewardsUnity(rewards JSONGameQuestStepRewardsUnity) JSONGameQuestStepRewards {
+	itemsReward := make([][]int, len(rewards.ItemsReward.Array))
+	for i, reward := range rewards.ItemsReward.Array {
+		itemsReward[i] = reward.Values.Array
+	}
+	return JSONGameQuestStepRewards{
+		Id:                        rewards.Id,
+		ExperienceRatio:           rewards.ExperienceRatio,
+		KamasRatio:                rewards.KamasRatio,
+		ItemsReward:               itemsReward,
+		KamasScaleWithPlayerLevel: unityBool(rewards.KamasScaleWithPlayerLevel),
+		LevelMax:                  rewards.LevelMax,
+		LevelMin:                  rewards.LevelMin,
+		StepId:                    rewards.StepId,
+	}
+}
+
+func convertQuestCategoryUnity(category JSONGameQuestCategoryUnity) JSONGameQuestCategory {
+	return JSONGameQuestCategory{
+		Id:       category.Id,
+		NameId:   category.NameId,
+		Order:    category.Order,
+		QuestIds: category.QuestIds.Array,
+	}
+}
+
+func convertAlmanaxCalendarUnity(calendar JSONGameAlamanaxCalendarUnity) JSONGameAlamanaxCalendar {
+	return JSONGameAlamanaxCalendar{
+		Id:         calendar.Id,
+		DescId:     calendar.DescId,
+		NameId:     calendar.NameId,
+		NpcId:      calendar.NpcId,
+		BonusesIds: calendar.BonusesIds.Array,
+		Dates:      calendar.Dates.Array,
+	}
+}
+
+func convertNPCUnity(npc JSONGameNPCUnity) JSONGameNPC {
+	converted := JSONGameNPC{
+		Id:      npc.Id,
+		NameId:  npc.NameId,
+		Actions: npc.Actions.Array,
+	}
+	for _, message := range npc.DialogMessages.Array {
+		converted.DialogMessages = append(converted.DialogMessages, message.Values.Array)
+	}
+	for _, reply := range npc.DialogReplies.Array {
+		converted.DialogReplies = append(converted.DialogReplies, reply.Values.Array)
+	}
+	return converted
+}
+
+func convertMonsterUnity(monster JSONGameMonsterUnity) JSONGameMonster {
+	converted := JSONGameMonster{
+		Id:                monster.Id,
+		NameId:            monster.NameId,
+		GfxId:             monster.GfxId,
+		Race:              monster.Race,
+		Grades:            monster.Grades.Array,
+		Subareas:          monster.Subareas.Array,
+		FavoriteSubareaId: monster.FavoriteSubareaId,
+		IsBoss:            unityBool(monster.IsBoss),
+		IsMiniBoss:        unityBool(monster.IsMiniBoss),
+		IsQuestMonster:    unityBool(monster.IsQuestMonster),
+	}
+	for _, drop := range monster.Drops.Array {
+		converted.Drops = append(converted.Drops, JSONGameMonsterDrop{
+			DropId:               drop.DropId,
+			MonsterId:            drop.MonsterId,
+			ObjectId:             drop.ObjectId,
+			PercentDropForGrade1: drop.PercentDropForGrade1,
+			PercentDropForGrade2: drop.PercentDropForGrade2,
+			PercentDropForGrade3: drop.PercentDropForGrade3,
+			PercentDropForGrade4: drop.PercentDropForGrade4,
+			PercentDropForGrade5: drop.PercentDropForGrade5,
+			Count:                drop.Count,
+			Criteria:             drop.Criteria,
+			HasCriteria:          unityBool(drop.HasCriteria),
+		})
+	}
+	return converted
+}
+
+func convertMonsterRaceUnity(race JSONGameMonsterRaceUnity) JSONGameMonsterRace {
+	return JSONGameMonsterRace{
+		Id:          race.Id,
+		SuperRaceId: race.SuperRaceId,
+		NameId:      race.NameId,
+		Monsters:    race.Monsters.Array,
+	}
+}
+
+func (d *unityGameData) AllMonsters() iter.Seq2[int, JSONGameMonster] {
+	return convertedAll(d.data.AllMonsters(), convertMonsterUnity)
+}
+
+func (d *unityGameData) MonsterRace(id int) (JSONGameMonsterRace, bool) {
+	return convertedLookup(d.data.MonsterRace, id, convertMonsterRaceUnity)
+}
+
+func convertSkillUnity(skill JSONGameSkillUnity) JSONGameSkill {
+	return JSONGameSkill{
+		Id:                    skill.Id,
+		NameId:                skill.NameId,
+		ParentJobId:           skill.ParentJobId,
+		IsForgemagus:          unityBool(skill.IsForgemagus),
+		ModifiableItemTypeIds: skill.ModifiableItemTypeIds.Array,
+		GatheredRessourceItem: skill.GatheredRessourceItem,
+		CraftableItemIds:      skill.CraftableItemIds.Array,
+		LevelMin:              skill.LevelMin,
+	}
+}
+
+func (d *unityGameData) Job(id int) (JSONGameJob, bool) {
+	return d.data.Job(id)
+}
+
+func (d *unityGameData) AllJobs() iter.Seq2[int, JSONGameJob] {
+	return d.data.AllJobs()
+}
+
+func (d *unityGameData) AllSkills() iter.Seq2[int, JSONGameSkill] {
+	return convertedAll(d.data.AllSkills(), convertSkillUnity)
+}
+
+func (d *unityGameData) NPC(id int) (JSONGameNPC, bool) {
+	return convertedLookup(d.data.NPC, id, convertNPCUnity)
+}
+
+func (d *unityGameData) AllNPCs() iter.Seq2[int, JSONGameNPC] {
+	return convertedAll(d.data.AllNPCs(), convertNPCUnity)
+}
+
+func (d *unityGameData) Item(id int) (JSONGameItem, bool) {
+	return convertedLookup(d.data.Item, id, convertItemUnity)
+}
+
+func (d *unityGameData) ItemType(id int) (JSONGameItemType, bool) {
+	return convertedLookup(d.data.ItemType, id, convertItemTypeUnity)
+}
+
+func (d *unityGameData) Set(id int) (JSONGameSet, bool) {
+	return convertedLookup(d.data.Set, id, convertSetUnity)
+}
+
+func (d *unityGameData) Effect(id int) (JSONGameEffect, bool) {
+	return convertedLookup(d.data.Effect, id, convertEffectUnity)
+}
+
+func (d *unityGameData) Spell(id int) (JSONGameSpell, bool) {
+	return convertedLookup(d.data.Spell, id, convertSpellUnity)
+}
+
+func (d *unityGameData) SpellType(id int) (JSONGameSpellType, bool) {
+	return d.data.SpellType(id)
+}
+
+func (d *unityGameData) SpellLevel(id int) (JSONGameSpellLevel, bool) {
+	return convertedLookup(d.data.SpellLevel, id, convertSpellLevelUnity)
+}
+
+func (d *unityGameData) Title(id int) (JSONGameTitle, bool) {
+	return convertedLookup(d.data.Title, id, convertTitleUnity)
+}
+
+func (d *unityGameData) Area(id int) (JSONGameArea, bool) {
+	return convertedLookup(d.data.Area, id, convertAreaUnity)
+}
+
+func (d *unityGameData) SuperArea(id int) (JSONGameSuperArea, bool) {
+	return convertedLookup(d.data.SuperArea, id, convertSuperAreaUnity)
+}
+
+func (d *unityGameData) SubArea(id int) (JSONGameSubArea, bool) {
+	return d.data.SubArea(id)
+}
+
+func (d *unityGameData) Mount(id int) (JSONGameMount, bool) {
+	return convertedLookup(d.data.Mount, id, convertMountUnity)
+}
+
+func (d *unityGameData) MountFamily(id int) (JSONGameMountFamily, bool) {
+	return convertedLookup(d.data.MountFamily, id, convertMountFamilyUnity)
+}
+
+func (d *unityGameData) Breed(id int) (JSONGameBreed, bool) {
+	return convertedLookup(d.data.Breed, id, convertBreedUnity)
+}
+
+func (d *unityGameData) Quest(id int) (JSONGameQuest, bool) {
+	return convertedLookup(d.data.Quest, id, convertQuestUnity)
+}
+
+func (d *unityGameData) QuestStep(id int) (JSONGameQuestStep, bool) {
+	return convertedLookup(d.data.QuestStep, id, convertQuestStepUnity)
+}
+
+func (d *unityGameData) QuestObjective(id int) (JSONGameQuestObjective, bool) {
+	return convertedLookup(d.data.QuestObjective, id, convertQuestObjectiveUnity)
+}
+
+func (d *unityGameData) QuestStepRewards(id int) (JSONGameQuestStepRewards, bool) {
+	return convertedLookup(d.data.QuestStepRewards, id, convertQuestStepRewardsUnity)
+}
+
+func (d *unityGameData) QuestCategory(id int) (JSONGameQuestCategory, bool) {
+	return convertedLookup(d.data.QuestCategory, id, convertQuestCategoryUnity)
+}
+
+func (d *unityGameData) AllItems() iter.Seq2[int, JSONGameItem] {
+	return convertedAll(d.data.AllItems(), convertItemUnity)
+}
+
+func (d *unityGameData) AllSets() iter.Seq2[int, JSONGameSet] {
+	return convertedAll(d.data.AllSets(), convertSetUnity)
+}
+
+func (d *unityGameData) AllSpells() iter.Seq2[int, JSONGameSpell] {
+	return convertedAll(d.data.AllSpells(), convertSpellUnity)
+}
+
+func (d *unityGameData) AllRecipes() iter.Seq2[int, JSONGameRecipe] {
+	return convertedAll(d.data.AllRecipes(), convertRecipeUnity)
+}
+
+func (d *unityGameData) AllBreeds() iter.Seq2[int, JSONGameBreed] {
+	return convertedAll(d.data.AllBreeds(), convertBreedUnity)
+}
+
+func (d *unityGameData) AllAreas() iter.Seq2[int, JSONGameArea] {
+	return convertedAll(d.data.AllAreas(), convertAreaUnity)
+}
+
+func (d *unityGameData) AllSubAreas() iter.Seq2[int, JSONGameSubArea] {
+	return d.data.AllSubAreas()
+}
+
+func (d *unityGameData) Emote(id int) (JSONGameEmote, bool) {
+	return d.data.Emote(id)
+}
+
+func (d *unityGameData) Achievement(id int) (JSONGameAchievement, bool) {
+	return d.data.Achievement(id)
+}
+
+func (d *unityGameData) AllTitles() iter.Seq2[int, JSONGameTitle] {
+	return convertedAll(d.data.AllTitles(), convertTitleUnity)
+}
+
+func (d *unityGameData) AllQuests() iter.Seq2[int, JSONGameQuest] {
+	return convertedAll(d.data.AllQuests(), convertQuestUnity)
+}
+
+func (d *unityGameData) AllMounts() iter.Seq2[int, JSONGameMount] {
+	return convertedAll(d.data.AllMounts(), convertMountUnity)
+}
+
+func (d *unityGameData) AllAlmanaxCalendars() iter.Seq2[int, JSONGameAlamanaxCalendar] {
+	return convertedAll(d.data.AllAlmanaxCalendars(), convertAlmanaxCalendarUnity)
+}
@@ -1,8 +1,6 @@
 module github.com/dofusdude/dodumap
 
-go 1.22.0
-
-toolchain go1.23.0
+go 1.23.0
 
 require (
 	github.com/charmbracelet/log v0.4.0
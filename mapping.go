@@ -1,61 +1,158 @@
 package dodumap
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 var Languages = []string{"fr", "en", "de", "es", "it", "pt"}
 
-func MapSets(data *JSONGameData, langs *map[string]LangDict) []MappedMultilangSet {
-	var mappedSets []MappedMultilangSet
-	for _, set := range data.Sets {
-		var mappedSet MappedMultilangSet
+// multilangText returns the translations of textId in every language of data.
+func multilangText(data GameData, textId int) map[string]string {
+	languages := data.Languages()
+	translations := make(map[string]string, len(languages))
+	for _, lang := range languages {
+		translations[lang] = data.Text(lang, textId)
+	}
+	return translations
+}
+
+// effectValues drops the effects that could not be mapped.
+func effectValues(effects []*MappedMultilangEffect) []MappedMultilangEffect {
+	var values []MappedMultilangEffect
+	for _, effect := range effects {
+		if effect != nil {
+			values = append(values, *effect)
+		}
+	}
+	return values
+}
+
+func MapSets(data *JSONGameData, langs *map[string]LangDict, ids *PersistedIds) ([]MappedMultilangSet, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	sets, err := mapSets(NewGameData(data, *langs), ids)
+	if sets == nil {
+		return nil, err
+	}
+
+	mappedSets := make([]MappedMultilangSet, len(sets))
+	for i, set := range sets {
+		mappedSets[i].AnkamaId = set.AnkamaId
+		mappedSets[i].Name = set.Name
+		mappedSets[i].ItemIds = set.ItemIds
+		mappedSets[i].Level = set.Level
+		mappedSets[i].IsCosmetic = set.ContainsCosmeticsOnly
+		for itemCombination := 1; itemCombination <= len(set.Effects); itemCombination++ {
+			var comboEffects []MappedMultilangSetEffect
+			for _, effect := range set.Effects[itemCombination] {
+				comboEffects = append(comboEffects, MappedMultilangSetEffect{
+					Min:              effect.Min,
+					Max:              effect.Max,
+					Type:             effect.Type,
+					Templated:        effect.Templated,
+					Active:           effect.Active,
+					ElementId:        effect.ElementId,
+					IsMeta:           effect.IsMeta,
+					MinMaxIrrelevant: effect.MinMaxIrrelevant,
+					ItemCombination:  uint(itemCombination),
+				})
+			}
+			if len(comboEffects) > 0 {
+				mappedSets[i].Effects = append(mappedSets[i].Effects, comboEffects)
+			}
+		}
+	}
+
+	return mappedSets, err
+}
+
+func mapSets(data GameData, ids *PersistedIds) ([]MappedMultilangSetUnity, error) {
+	if !ids.loaded() {
+		return nil, ErrRegistryNotLoaded
+	}
+
+	var errs []error
+	var mappedSets []MappedMultilangSetUnity
+	for _, set := range data.AllSets() {
+		var mappedSet MappedMultilangSetUnity
 		mappedSet.AnkamaId = set.Id
 		mappedSet.ItemIds = set.ItemIds
-		mappedSet.Effects = ParseItemCombo(set.Effects, ParseEffects(data, set.Effects, langs))
+		parsedEffects, err := parseEffects(data, set.Effects, ids.Elements, true)
+		if err != nil {
+			errs = append(errs, newDataError("item_sets.json", set.Id, "effects", err))
+		}
+
+		parseCombi := ParseItemComboUnity(parsedEffects)
+		if len(parseCombi) > 0 {
+			mappedSet.Effects = parseCombi
+		}
 
 		allItemsCosmetic := len(set.ItemIds) > 0
+		mappedSet.ContainsCosmetics = false
 
 		highestLevel := 0
-		for _, item := range set.ItemIds {
-			if data.Items[item].Level > highestLevel {
-				highestLevel = data.Items[item].Level
+		for _, itemId := range set.ItemIds {
+			item, _ := data.Item(itemId)
+			if item.Level > highestLevel {
+				highestLevel = item.Level
 			}
 
 			// 5 == "cosmetic"
-			if data.ItemTypes[data.Items[item].TypeId].CategoryId != 5 {
+			itemType, _ := data.ItemType(item.TypeId)
+			if itemType.CategoryId == 5 {
+				mappedSet.ContainsCosmetics = true
+			} else {
 				allItemsCosmetic = false
 			}
 		}
 
-		mappedSet.IsCosmetic = allItemsCosmetic
+		mappedSet.ContainsCosmeticsOnly = allItemsCosmetic
 		mappedSet.Level = highestLevel
 
 		mappedSet.Name = make(map[string]string)
-		for _, lang := range Languages {
-			mappedSet.Name[lang] = (*langs)[lang].Texts[set.NameId]
+		for _, lang := range data.Languages() {
+			mappedSet.Name[lang] = data.Text(lang, set.NameId)
 		}
 
 		mappedSets = append(mappedSets, mappedSet)
 	}
 
 	if len(mappedSets) == 0 {
-		return nil
+		return nil, errors.Join(errs...)
+	}
+
+	return mappedSets, errors.Join(errs...)
+}
+
+func MapRecipes(data *JSONGameData) ([]MappedMultilangRecipe, error) {
+	if data == nil {
+		return nil, ErrNilInput
 	}
 
-	return mappedSets
+	return mapRecipes(NewGameData(data, nil))
 }
 
-func MapRecipes(data *JSONGameData) []MappedMultilangRecipe {
+func mapRecipes(data GameData) ([]MappedMultilangRecipe, error) {
+	var errs []error
 	var mappedRecipes []MappedMultilangRecipe
 
-	for _, recipe := range data.Recipes {
+	for _, recipe := range data.AllRecipes() {
 		ingredientCount := len(recipe.IngredientIds)
+		if len(recipe.Quantities) != ingredientCount {
+			errs = append(errs, newDataError("recipes.json", recipe.Id, "quantities", fmt.Errorf("%d quantities for %d ingredients", len(recipe.Quantities), ingredientCount)))
+			continue
+		}
 		var mappedRecipe MappedMultilangRecipe
 		mappedRecipe.ResultId = recipe.Id
+		mappedRecipe.JobId = recipe.JobId
+		mappedRecipe.SkillId = recipe.SkillId
+		mappedRecipe.Level = recipe.Level
 		mappedRecipe.Entries = make([]MappedMultilangRecipeEntry, ingredientCount)
 		for i := range ingredientCount {
 			var recipeEntry MappedMultilangRecipeEntry
@@ -67,65 +164,138 @@ func MapRecipes(data *JSONGameData) []MappedMultilangRecipe {
 	}
 
 	if len(mappedRecipes) == 0 {
-		return nil
+		return nil, errors.Join(errs...)
 	}
 
-	return mappedRecipes
+	return mappedRecipes, errors.Join(errs...)
 }
 
-func MapMounts(data *JSONGameData, langs *map[string]LangDict) []MappedMultilangMount {
+func MapMounts(data *JSONGameData, langs *map[string]LangDict, ids *PersistedIds) ([]MappedMultilangMount, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapMounts(NewGameData(data, *langs), ids)
+}
+
+func mapMounts(data GameData, ids *PersistedIds) ([]MappedMultilangMount, error) {
+	if !ids.loaded() {
+		return nil, ErrRegistryNotLoaded
+	}
+
+	var errs []error
 	var mappedMounts []MappedMultilangMount
-	for _, mount := range data.Mounts {
+	for _, mount := range data.AllMounts() {
 		var mappedMount MappedMultilangMount
 		mappedMount.AnkamaId = mount.Id
 		mappedMount.FamilyId = mount.FamilyId
 		mappedMount.Name = make(map[string]string)
 		mappedMount.FamilyName = make(map[string]string)
 
-		for _, lang := range Languages {
-			mappedMount.Name[lang] = (*langs)[lang].Texts[mount.NameId]
-			mappedMount.FamilyName[lang] = (*langs)[lang].Texts[data.MountFamilys[mount.FamilyId].NameId]
+		family, _ := data.MountFamily(mount.FamilyId)
+		for _, lang := range data.Languages() {
+			mappedMount.Name[lang] = data.Text(lang, mount.NameId)
+			mappedMount.FamilyName[lang] = data.Text(lang, family.NameId)
 		}
 
 		effectsArr := make([][]*JSONGameItemPossibleEffect, 1)
 		effectsArr[0] = mount.Effects
-		allEffectResult := ParseEffects(data, effectsArr, langs)
+		allEffectResult, err := parseEffects(data, effectsArr, ids.Elements, true)
+		if err != nil {
+			errs = append(errs, newDataError("mounts.json", mount.Id, "effects", err))
+		}
 		if len(allEffectResult) > 0 {
-			mappedMount.Effects = allEffectResult[0]
+			mappedMount.Effects = effectValues(allEffectResult[0])
 		}
 
 		mappedMounts = append(mappedMounts, mappedMount)
 	}
 
 	if len(mappedMounts) == 0 {
-		return nil
+		return nil, errors.Join(errs...)
 	}
 
-	return mappedMounts
+	return mappedMounts, errors.Join(errs...)
 }
 
+// questKamasReward is the kamas for a step. Rewards that scale with the player level use maxLevel, unless
+// there is none (-1).
 func questKamasReward(maxLevel int, optimalLevel int, kamasRatio float64, duration float64, scaleWithPlayerLevel bool) int {
 	lvl := optimalLevel
-	if scaleWithPlayerLevel {
+	if scaleWithPlayerLevel && maxLevel >= 0 {
 		lvl = maxLevel
 	}
 	return int((float64(lvl*lvl+20*lvl-20) * kamasRatio * duration))
 }
 
-func MapAlmanax(data *JSONGameData, langs *map[string]LangDict) []MappedMultilangNPCAlmanax {
-	var mappedAlmanax []MappedMultilangNPCAlmanax
+// questExperienceReward is the experience for a step at level, which is capped by maxLevel when the step has one.
+func questExperienceReward(level int, maxLevel int, experienceRatio float64, duration float64) int {
+	if maxLevel > 0 && level > maxLevel {
+		level = maxLevel
+	}
+	lvl := float64(level)
+	return int(lvl * (100 + 2*lvl) * (100 + 2*lvl) / 20 * experienceRatio * duration)
+}
+
+func MapAlmanax(data *JSONGameData, langs *map[string]LangDict) ([]MappedMultilangNPCAlmanax, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	almanax, err := mapAlmanax(NewGameData(data, *langs))
+	if almanax == nil {
+		return nil, err
+	}
+
+	mappedAlmanax := make([]MappedMultilangNPCAlmanax, len(almanax))
+	for i, npcAlmanax := range almanax {
+		mappedAlmanax[i].OfferingReceiver = npcAlmanax.OfferingReceiver
+		mappedAlmanax[i].NpcId = npcAlmanax.NpcId
+		mappedAlmanax[i].Days = npcAlmanax.Days
+		mappedAlmanax[i].Offering.ItemId = npcAlmanax.Offering.ItemId
+		mappedAlmanax[i].Offering.ItemName = npcAlmanax.Offering.ItemName
+		mappedAlmanax[i].Offering.Quantity = npcAlmanax.Offering.Quantity
+		mappedAlmanax[i].Bonus = npcAlmanax.Bonus
+		mappedAlmanax[i].BonusType = npcAlmanax.BonusType
+		mappedAlmanax[i].RewardKamas = npcAlmanax.RewardKamas
+
+		ImgBaseUrl := "https://api.dofusdu.de/dofus2/img/item/" + strconv.Itoa(data.Items[npcAlmanax.Offering.ItemId].IconId)
+		mappedAlmanax[i].Offering.ImageUrls.HD = ImgBaseUrl + "-800.png"
+		mappedAlmanax[i].Offering.ImageUrls.HQ = ImgBaseUrl + "-400.png"
+		mappedAlmanax[i].Offering.ImageUrls.SD = ImgBaseUrl + "-200.png"
+		mappedAlmanax[i].Offering.ImageUrls.Icon = ImgBaseUrl + ".png"
+	}
+
+	return mappedAlmanax, err
+}
+
+func mapAlmanax(data GameData) ([]MappedMultilangNPCAlmanaxUnity, error) {
+	var errs []error
+	var mappedAlmanax []MappedMultilangNPCAlmanaxUnity
 
-	for _, almCat := range data.questCategories[31].QuestIds {
-		quest := data.quests[almCat]
-		if (*langs)["en"].Texts[quest.NameId][:8] != "Offering" {
+	almanaxCategory, _ := data.QuestCategory(31)
+	for _, almCat := range almanaxCategory.QuestIds {
+		quest, _ := data.Quest(almCat)
+		questName := data.Text("en", quest.NameId)
+		if len(questName) < 13 || questName[:8] != "Offering" {
 			continue
 		}
 
-		step := data.questSteps[quest.StepIds[0]]
-		objective := data.questObjectives[step.ObjectiveIds[0]].Parameters
-		item := data.Items[objective.Parameter1]
-		itemQuantity := objective.Parameter2
-		stepRewards := data.questStepRewards[step.RewardsIds[0]]
+		if len(quest.StepIds) == 0 {
+			errs = append(errs, newDataError("quests.json", quest.Id, "stepIds", ErrMissingReference))
+			continue
+		}
+		step, _ := data.QuestStep(quest.StepIds[0])
+		if len(step.ObjectiveIds) < 3 || len(step.RewardsIds) == 0 {
+			errs = append(errs, newDataError("quest_steps.json", step.Id, "objectiveIds", ErrMissingReference))
+			continue
+		}
+		objective, _ := data.QuestObjective(step.ObjectiveIds[0])
+		item, _ := data.Item(objective.Parameters.Parameter1)
+		itemType, _ := data.ItemType(item.TypeId)
+
+		itemQuantity := objective.Parameters.Parameter2
+		stepRewards, _ := data.QuestStepRewards(step.RewardsIds[0])
 
 		kamasRatio := stepRewards.KamasRatio
 		maxLevel := stepRewards.LevelMax
@@ -134,11 +304,13 @@ func MapAlmanax(data *JSONGameData, langs *map[string]LangDict) []MappedMultilan
 		optimalLevel := step.OptimalLevel
 
 		rewardKamas := questKamasReward(maxLevel, optimalLevel, kamasRatio, duration, kamasScaleWithPlayerLevel)
-		questObjectiveNpc := data.questObjectives[step.ObjectiveIds[2]].Parameters.Parameter0
+		experienceRatio := stepRewards.ExperienceRatio
+		npcObjective, _ := data.QuestObjective(step.ObjectiveIds[2])
+		questObjectiveNpc := npcObjective.Parameters.Parameter0
 
 		var currAlm JSONGameAlamanaxCalendar
 		found := false
-		for _, almCal := range data.almanaxCalendars {
+		for _, almCal := range data.AllAlmanaxCalendars() {
 			if almCal.NpcId == questObjectiveNpc {
 				currAlm = almCal
 				found = true
@@ -146,97 +318,113 @@ func MapAlmanax(data *JSONGameData, langs *map[string]LangDict) []MappedMultilan
 			}
 		}
 		if !found {
-			log.Printf("Could not find almanax calendar for NPC %d", questObjectiveNpc)
+			errs = append(errs, newDataError("almanax.json", -1, "npcId", fmt.Errorf("no calendar for npc %d: %w", questObjectiveNpc, ErrMissingReference)))
+			continue
+		}
+
+		var mappedNPCAlmanax MappedMultilangNPCAlmanaxUnity
+		mappedNPCAlmanax.OptimalLevel = optimalLevel
+		mappedNPCAlmanax.Duration = duration
+		mappedNPCAlmanax.ExperienceRatio = experienceRatio
+		mappedNPCAlmanax.DatesRule = currAlm.Dates
+
+		// the name is the same in all languages
+		mappedNPCAlmanax.NpcId = questObjectiveNpc
+		npc, found := data.NPC(questObjectiveNpc)
+		if npcName := data.Text("en", npc.NameId); found && npcName != "" {
+			mappedNPCAlmanax.OfferingReceiver = npcName
+		} else {
+			mappedNPCAlmanax.OfferingReceiver = questName[13:] // remove "Offering to "
 		}
 
-		var mappedNPCAlmanax MappedMultilangNPCAlmanax
-		mappedNPCAlmanax.OfferingReceiver = (*langs)["en"].Texts[quest.NameId][13:] // remove "Offering to ". The name is the same in all languages.
 		itemNames := make(map[string]string)
 		mappedNPCAlmanax.Bonus = make(map[string]string)
 		mappedNPCAlmanax.BonusType = make(map[string]string)
-		for _, lang := range Languages {
-			itemNames[lang] = (*langs)[lang].Texts[item.NameId]
-			mappedNPCAlmanax.Bonus[lang] = (*langs)[lang].Texts[currAlm.DescId]
-			mappedNPCAlmanax.BonusType[lang] = (*langs)[lang].Texts[currAlm.NameId]
+		for _, lang := range data.Languages() {
+			itemNames[lang] = data.Text(lang, item.NameId)
+			mappedNPCAlmanax.Bonus[lang] = data.Text(lang, currAlm.DescId)
+			mappedNPCAlmanax.BonusType[lang] = data.Text(lang, currAlm.NameId)
 
 			mappedNPCAlmanax.Bonus[lang] = strings.ReplaceAll(mappedNPCAlmanax.Bonus[lang], "<b>", "")
 			mappedNPCAlmanax.Bonus[lang] = strings.ReplaceAll(mappedNPCAlmanax.Bonus[lang], "</b>", "")
 		}
 		mappedNPCAlmanax.Offering.ItemId = item.Id
+		mappedNPCAlmanax.Offering.ItemCategoryId = itemType.CategoryId
 		mappedNPCAlmanax.Offering.ItemName = itemNames
 		mappedNPCAlmanax.Offering.Quantity = itemQuantity
 		mappedNPCAlmanax.RewardKamas = rewardKamas
-
-		ImgBaseUrl := "https://api.dofusdu.de/dofus2/img/item/" + strconv.Itoa(item.IconId)
-		mappedNPCAlmanax.Offering.ImageUrls.HD = ImgBaseUrl + "-800.png"
-		mappedNPCAlmanax.Offering.ImageUrls.HQ = ImgBaseUrl + "-400.png"
-		mappedNPCAlmanax.Offering.ImageUrls.SD = ImgBaseUrl + "-200.png"
-		mappedNPCAlmanax.Offering.ImageUrls.Icon = ImgBaseUrl + ".png"
-
 		mappedAlmanax = append(mappedAlmanax, mappedNPCAlmanax)
 	}
 
 	if len(mappedAlmanax) == 0 {
-		return nil
+		return nil, errors.Join(errs...)
 	}
 
-	return mappedAlmanax
+	return mappedAlmanax, errors.Join(errs...)
 }
 
-func MapItems(data *JSONGameData, langs *map[string]LangDict) []MappedMultilangItem {
+func MapItems(data *JSONGameData, langs *map[string]LangDict, ids *PersistedIds) ([]MappedMultilangItem, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapItems(NewGameData(data, *langs), ids)
+}
+
+func mapItems(data GameData, ids *PersistedIds) ([]MappedMultilangItem, error) {
+	if !ids.loaded() {
+		return nil, ErrRegistryNotLoaded
+	}
+
+	languages := data.Languages()
+	var errs []error
 	var filteredItems []JSONGameItem
 
-	for key, value := range data.Items {
-		if langs == nil || data == nil {
-			log.Fatal("langs is nil")
-		}
-		frName := (*langs)["fr"].Texts[value.NameId]
-		itemType := data.ItemTypes[value.TypeId]
+	for _, item := range data.AllItems() {
+		frName := data.Text("fr", item.NameId)
+		itemType, _ := data.ItemType(item.TypeId)
 		category := itemType.CategoryId
-		deTypeName := (*langs)["de"].Texts[data.ItemTypes[value.TypeId].NameId]
+		deTypeName := data.Text("de", itemType.NameId)
 		if frName == "" || category == 4 || deTypeName == "Hauptquesten" {
 			continue // skip unnamed and hidden items
 		}
-		filteredItems = append(filteredItems, data.Items[key])
+		filteredItems = append(filteredItems, item)
 	}
 
 	mappedItems := make([]MappedMultilangItem, len(filteredItems))
 	for idx, item := range filteredItems {
+		itemType, _ := data.ItemType(item.TypeId)
 		mappedItems[idx].AnkamaId = item.Id
 		mappedItems[idx].Level = item.Level
 		mappedItems[idx].Pods = item.Pods
 		mappedItems[idx].Image = fmt.Sprintf("https://static.ankama.com/dofus/www/game/items/200/%d.png", item.IconId)
-		mappedItems[idx].Name = make(map[string]string, len(Languages))
-		mappedItems[idx].Description = make(map[string]string, len(Languages))
-		mappedItems[idx].Type.Name = make(map[string]string, len(Languages))
+		mappedItems[idx].Name = make(map[string]string, len(languages))
+		mappedItems[idx].Description = make(map[string]string, len(languages))
+		mappedItems[idx].Type.Name = make(map[string]string, len(languages))
 		mappedItems[idx].IconId = item.IconId
 
-		for _, lang := range Languages {
-			mappedItems[idx].Name[lang] = (*langs)[lang].Texts[item.NameId]
-			mappedItems[idx].Description[lang] = (*langs)[lang].Texts[item.DescriptionId]
-			mappedItems[idx].Type.Name[lang] = (*langs)[lang].Texts[data.ItemTypes[item.TypeId].NameId]
+		for _, lang := range languages {
+			mappedItems[idx].Name[lang] = data.Text(lang, item.NameId)
+			mappedItems[idx].Description[lang] = data.Text(lang, item.DescriptionId)
+			mappedItems[idx].Type.Name[lang] = data.Text(lang, itemType.NameId)
 		}
 
 		mappedItems[idx].Type.Id = item.TypeId
-		mappedItems[idx].Type.SuperTypeId = data.ItemTypes[item.TypeId].SuperTypeId
-		mappedItems[idx].Type.CategoryId = data.ItemTypes[item.TypeId].CategoryId
+		mappedItems[idx].Type.SuperTypeId = itemType.SuperTypeId
+		mappedItems[idx].Type.CategoryId = itemType.CategoryId
 
 		searchTypeEn := mappedItems[idx].Type.Name["en"]
-		key, foundKey := PersistedTypes.Entries.GetKey(searchTypeEn)
-		if foundKey {
-			mappedItems[idx].Type.ItemTypeId = key.(int)
-		} else {
-			mappedItems[idx].Type.ItemTypeId = PersistedTypes.NextId
-			PersistedTypes.Entries.Put(PersistedTypes.NextId, searchTypeEn)
-			PersistedTypes.NextId++
-		}
+		mappedItems[idx].Type.ItemTypeId, _ = ids.Types.GetOrAssign(searchTypeEn)
 
 		mappedItems[idx].UsedInRecipes = item.RecipeIds
 		effectsArr := make([][]*JSONGameItemPossibleEffect, 1)
 		effectsArr[0] = item.PossibleEffects
-		allEffectResult := ParseEffects(data, effectsArr, langs)
+		allEffectResult, err := parseEffects(data, effectsArr, ids.Elements, true)
+		if err != nil {
+			errs = append(errs, newDataError("items.json", item.Id, "possibleEffects", err))
+		}
 		if len(allEffectResult) > 0 {
-			mappedItems[idx].Effects = allEffectResult[0]
+			mappedItems[idx].Effects = effectValues(allEffectResult[0])
 		}
 		mappedItems[idx].Range = item.Range
 		mappedItems[idx].MinRange = item.MinRange
@@ -248,17 +436,651 @@ func MapItems(data *JSONGameData, langs *map[string]LangDict) []MappedMultilangI
 		mappedItems[idx].DropMonsterIds = item.DropMonsterIds
 		mappedItems[idx].HasParentSet = item.ItemSetId != -1
 		if mappedItems[idx].HasParentSet {
+			parentSet, _ := data.Set(item.ItemSetId)
 			mappedItems[idx].ParentSet.Id = item.ItemSetId
-			mappedItems[idx].ParentSet.Name = make(map[string]string, len(Languages))
-			for _, lang := range Languages {
-				mappedItems[idx].ParentSet.Name[lang] = (*langs)[lang].Texts[data.Sets[item.ItemSetId].NameId]
+			mappedItems[idx].ParentSet.Name = make(map[string]string, len(languages))
+			for _, lang := range languages {
+				mappedItems[idx].ParentSet.Name[lang] = data.Text(lang, parentSet.NameId)
 			}
 		}
 
 		if len(item.Criteria) != 0 && mappedItems[idx].Type.Name["de"] != "Verwendbarer Temporis-Gegenstand" { // TODO Temporis got some weird conditions, need to play to see the items, not in normal game
-			mappedItems[idx].Conditions, mappedItems[idx].ConditionTree = ParseCondition(item.Criteria, langs, data)
+			mappedItems[idx].Conditions, mappedItems[idx].ConditionTree, err = parseCondition(item.Criteria, data, ids.Elements)
+			if err != nil {
+				errs = append(errs, newDataError("items.json", item.Id, data.dialect().criteriaField, err))
+			}
+		}
+	}
+
+	return mappedItems, errors.Join(errs...)
+}
+
+func MapSpells(data *JSONGameData, langs *map[string]LangDict, ids *PersistedIds) ([]MappedMultilangSpell, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapSpells(NewGameData(data, *langs), ids)
+}
+
+func mapSpells(data GameData, ids *PersistedIds) ([]MappedMultilangSpell, error) {
+	if !ids.loaded() {
+		return nil, ErrRegistryNotLoaded
+	}
+
+	languages := data.Languages()
+	var errs []error
+	var mappedSpells []MappedMultilangSpell
+	for _, spell := range data.AllSpells() {
+		if data.Text("fr", spell.NameId) == "" {
+			continue // skip unnamed spells
+		}
+
+		var mappedSpell MappedMultilangSpell
+		mappedSpell.AnkamaId = spell.Id
+		mappedSpell.IconId = spell.IconId
+		mappedSpell.Order = spell.Order
+		mappedSpell.Name = make(map[string]string, len(languages))
+		mappedSpell.Description = make(map[string]string, len(languages))
+
+		spellType, _ := data.SpellType(spell.TypeId)
+		mappedSpell.Type.Id = spell.TypeId
+		mappedSpell.Type.LongName = make(map[string]string, len(languages))
+		mappedSpell.Type.ShortName = make(map[string]string, len(languages))
+
+		for _, lang := range languages {
+			mappedSpell.Name[lang] = data.Text(lang, spell.NameId)
+			mappedSpell.Description[lang] = data.Text(lang, spell.DescriptionId)
+			mappedSpell.Type.LongName[lang] = data.Text(lang, spellType.LongNameId)
+			mappedSpell.Type.ShortName[lang] = data.Text(lang, spellType.ShortNameId)
+		}
+
+		for _, levelId := range spell.SpellLevels {
+			level, found := data.SpellLevel(levelId)
+			if !found {
+				errs = append(errs, newDataError("spells.json", spell.Id, "spellLevels", fmt.Errorf("level %d: %w", levelId, ErrMissingReference)))
+				continue
+			}
+
+			var mappedLevel MappedMultilangSpellLevel
+			mappedLevel.AnkamaId = level.Id
+			mappedLevel.Grade = level.Grade
+			mappedLevel.MinPlayerLevel = level.MinPlayerLevel
+			mappedLevel.ApCost = level.ApCost
+			mappedLevel.MinRange = level.MinRange
+			mappedLevel.Range = level.Range
+			mappedLevel.RangeCanBeBoosted = level.RangeCanBeBoosted
+			mappedLevel.CastInLine = level.CastInLine
+			mappedLevel.CastInDiagonal = level.CastInDiagonal
+			mappedLevel.CastTestLos = level.CastTestLos
+			mappedLevel.CriticalHitProbability = level.CriticalHitProbability
+			mappedLevel.MaxCastPerTurn = level.MaxCastPerTurn
+			mappedLevel.MaxCastPerTarget = level.MaxCastPerTarget
+			mappedLevel.Cooldown = level.MinCastInterval
+			mappedLevel.InitialCooldown = level.InitialCooldown
+			mappedLevel.GlobalCooldown = level.GlobalCooldown
+
+			// spell effects only reuse the ids of item effects, the registry is for item filters
+			allEffectResult, err := parseEffects(data, [][]*JSONGameItemPossibleEffect{level.Effects, level.CriticalEffect}, ids.Elements, false)
+			if err != nil {
+				errs = append(errs, newDataError("spell_levels.json", level.Id, "effects", err))
+			}
+			if len(allEffectResult) == 2 {
+				mappedLevel.Effects = effectValues(allEffectResult[0])
+				mappedLevel.CriticalEffects = effectValues(allEffectResult[1])
+			}
+
+			mappedSpell.Levels = append(mappedSpell.Levels, mappedLevel)
+		}
+		slices.SortStableFunc(mappedSpell.Levels, func(a, b MappedMultilangSpellLevel) int {
+			return a.Grade - b.Grade
+		})
+
+		mappedSpells = append(mappedSpells, mappedSpell)
+	}
+
+	if len(mappedSpells) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return mappedSpells, errors.Join(errs...)
+}
+
+func MapBreeds(data *JSONGameData, langs *map[string]LangDict) ([]MappedMultilangBreed, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapBreeds(NewGameData(data, *langs))
+}
+
+func mapBreeds(data GameData) ([]MappedMultilangBreed, error) {
+	var errs []error
+	var mappedBreeds []MappedMultilangBreed
+	for _, breed := range data.AllBreeds() {
+		textIds := []struct {
+			field string
+			id    int
+		}{
+			{"shortNameId", breed.ShortNameId},
+			{"longNameId", breed.LongNameId},
+			{"gameplayClassDescriptionId", breed.GameplayClassDescriptionId},
+		}
+		for _, textId := range textIds {
+			if textId.id < 0 { // Dofus 3 stores them as strings that failed to parse
+				errs = append(errs, newDataError("breeds.json", breed.Id, textId.field, fmt.Errorf("invalid text id: %w", ErrMissingReference)))
+			}
+		}
+
+		var mappedBreed MappedMultilangBreed
+		mappedBreed.AnkamaId = breed.Id
+		mappedBreed.SpellIds = breed.BreedSpellsId
+		mappedBreed.Complexity = breed.Complexity
+		mappedBreed.SortIndex = breed.SortIndex
+		mappedBreed.ShortName = multilangText(data, breed.ShortNameId)
+		mappedBreed.LongName = multilangText(data, breed.LongNameId)
+		mappedBreed.Description = multilangText(data, breed.DescriptionId)
+		mappedBreed.GameplayDescription = multilangText(data, breed.GameplayClassDescriptionId)
+
+		roles := slices.Clone(breed.BreedRoles)
+		slices.SortStableFunc(roles, func(a, b JSONGameBreedRole) int {
+			return a.Order - b.Order
+		})
+		for _, role := range roles {
+			mappedBreed.Roles = append(mappedBreed.Roles, MappedMultilangBreedRole{
+				RoleId:      role.RoleId,
+				Value:       role.Value,
+				Description: multilangText(data, role.DescriptionId),
+			})
+		}
+
+		if breed.GuideItemId > 0 {
+			guideItem, found := data.Item(breed.GuideItemId)
+			if found {
+				mappedBreed.GuideItem = &MappedMultilangItemLink{
+					Id:   guideItem.Id,
+					Name: multilangText(data, guideItem.NameId),
+				}
+			} else {
+				errs = append(errs, newDataError("breeds.json", breed.Id, "guideItemId", fmt.Errorf("item %d: %w", breed.GuideItemId, ErrMissingReference)))
+			}
+		}
+
+		mappedBreeds = append(mappedBreeds, mappedBreed)
+	}
+
+	if len(mappedBreeds) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return mappedBreeds, errors.Join(errs...)
+}
+
+func MapQuests(data *JSONGameData, langs *map[string]LangDict, ids *PersistedIds) ([]MappedMultilangQuest, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapQuests(NewGameData(data, *langs), ids)
+}
+
+func mapQuests(data GameData, ids *PersistedIds) ([]MappedMultilangQuest, error) {
+	if !ids.loaded() {
+		return nil, ErrRegistryNotLoaded
+	}
+
+	criteriaData := withQuestCriteria(data)
+	var errs []error
+	var mappedQuests []MappedMultilangQuest
+	for _, quest := range data.AllQuests() {
+		var mappedQuest MappedMultilangQuest
+		mappedQuest.AnkamaId = quest.Id
+		mappedQuest.Name = multilangText(data, quest.NameId)
+		mappedQuest.LevelMin = quest.LevelMin
+		mappedQuest.LevelMax = quest.LevelMax
+		mappedQuest.RepeatType = quest.RepeatType
+		mappedQuest.RepeatLimit = quest.RepeatLimit
+		mappedQuest.IsDungeonQuest = quest.IsDungeonQuest
+		mappedQuest.IsPartyQuest = quest.IsPartyQuest
+		mappedQuest.Followable = quest.Followable
+
+		var err error
+		_, mappedQuest.StartCondition, err = parseCondition(quest.StartCriterion, criteriaData, ids.Elements)
+		if err != nil {
+			errs = append(errs, newDataError("quests.json", quest.Id, "startCriterion", err))
+		}
+
+		category, found := data.QuestCategory(quest.CategoryId)
+		if !found {
+			errs = append(errs, newDataError("quests.json", quest.Id, "categoryId", fmt.Errorf("category %d: %w", quest.CategoryId, ErrMissingReference)))
+		}
+		mappedQuest.Category.Id = quest.CategoryId
+		mappedQuest.Category.Name = multilangText(data, category.NameId)
+		mappedQuest.Category.Order = category.Order
+
+		for _, stepId := range quest.StepIds {
+			step, found := data.QuestStep(stepId)
+			if !found {
+				errs = append(errs, newDataError("quests.json", quest.Id, "stepIds", fmt.Errorf("step %d: %w", stepId, ErrMissingReference)))
+				continue
+			}
+
+			var mappedStep MappedMultilangQuestStep
+			mappedStep.AnkamaId = step.Id
+			mappedStep.Name = multilangText(data, step.NameId)
+			mappedStep.Description = multilangText(data, step.DescriptionId)
+			mappedStep.OptimalLevel = step.OptimalLevel
+			mappedStep.Duration = step.Duration
+
+			for _, objectiveId := range step.ObjectiveIds {
+				objective, found := data.QuestObjective(objectiveId)
+				if !found {
+					errs = append(errs, newDataError("quest_steps.json", step.Id, "objectiveIds", fmt.Errorf("objective %d: %w", objectiveId, ErrMissingReference)))
+					continue
+				}
+				parameters := []int{objective.Parameters.Parameter0, objective.Parameters.Parameter1, objective.Parameters.Parameter2, objective.Parameters.Parameter3, objective.Parameters.Parameter4}
+				if objective.Parameters.NumParams > 0 && objective.Parameters.NumParams < len(parameters) {
+					parameters = parameters[:objective.Parameters.NumParams]
+				}
+				mappedStep.Objectives = append(mappedStep.Objectives, MappedMultilangQuestObjective{
+					AnkamaId:    objective.Id,
+					TypeId:      objective.TypeId,
+					MapId:       objective.MapId,
+					Coords:      objective.Coords,
+					DungeonOnly: objective.Parameters.DungeonOnly,
+					Parameters:  parameters,
+				})
+			}
+
+			for _, rewardsId := range step.RewardsIds {
+				rewards, found := data.QuestStepRewards(rewardsId)
+				if !found {
+					errs = append(errs, newDataError("quest_steps.json", step.Id, "rewardsIds", fmt.Errorf("rewards %d: %w", rewardsId, ErrMissingReference)))
+					continue
+				}
+				mappedRewards := MappedMultilangQuestReward{
+					AnkamaId:                  rewards.Id,
+					Kamas:                     questKamasReward(rewards.LevelMax, step.OptimalLevel, rewards.KamasRatio, step.Duration, rewards.KamasScaleWithPlayerLevel),
+					Experience:                questExperienceReward(step.OptimalLevel, rewards.LevelMax, rewards.ExperienceRatio, step.Duration),
+					KamasScaleWithPlayerLevel: rewards.KamasScaleWithPlayerLevel,
+					LevelMin:                  rewards.LevelMin,
+					LevelMax:                  rewards.LevelMax,
+				}
+				for _, itemReward := range rewards.ItemsReward {
+					if len(itemReward) != 2 { // item id and quantity
+						errs = append(errs, newDataError("quest_step_rewards.json", rewards.Id, "itemsReward", fmt.Errorf("reward with %d values", len(itemReward))))
+						continue
+					}
+					mappedRewards.Items = append(mappedRewards.Items, MappedQuestItemReward{ItemId: itemReward[0], Quantity: itemReward[1]})
+				}
+				mappedStep.Rewards = append(mappedStep.Rewards, mappedRewards)
+			}
+
+			mappedQuest.Steps = append(mappedQuest.Steps, mappedStep)
+		}
+
+		mappedQuests = append(mappedQuests, mappedQuest)
+	}
+
+	if len(mappedQuests) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return mappedQuests, errors.Join(errs...)
+}
+
+func MapTitles(data *JSONGameData, langs *map[string]LangDict) ([]MappedMultilangTitle, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapTitles(NewGameData(data, *langs))
+}
+
+// mapTitle resolves the names of title for all languages.
+func mapTitle(data GameData, title JSONGameTitle) MappedMultilangTitle {
+	mappedTitle := MappedMultilangTitle{
+		AnkamaId:   title.Id,
+		NameMale:   make(map[string]string),
+		NameFemale: make(map[string]string),
+		Visible:    title.Visible,
+		CategoryId: title.CategoryId,
+	}
+	for _, lang := range data.Languages() {
+		mappedTitle.NameMale[lang] = data.Text(lang, title.NameMaleId)
+		femaleName, found := data.LookupText(lang, title.NameFemaleId)
+		if !found || femaleName == "" {
+			femaleName = mappedTitle.NameMale[lang]
+		}
+		mappedTitle.NameFemale[lang] = femaleName
+	}
+	return mappedTitle
+}
+
+func mapTitles(data GameData) ([]MappedMultilangTitle, error) {
+	var errs []error
+	var mappedTitles []MappedMultilangTitle
+	for _, title := range data.AllTitles() {
+		if title.NameMaleId < 0 {
+			errs = append(errs, newDataError("titles.json", title.Id, "nameMaleId", ErrMissingReference))
+			continue
+		}
+		mappedTitles = append(mappedTitles, mapTitle(data, title))
+	}
+
+	return mappedTitles, errors.Join(errs...)
+}
+
+func MapAreas(data *JSONGameData, langs *map[string]LangDict) ([]MappedMultilangSuperArea, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapAreas(NewGameData(data, *langs))
+}
+
+// mapAreas returns the areas grouped by their super area with their sub-areas, all ordered by id.
+func mapAreas(data GameData) ([]MappedMultilangSuperArea, error) {
+	var errs []error
+	var mappedSuperAreas []MappedMultilangSuperArea
+	superAreaIdx := make(map[int]int)
+	areaIdx := make(map[int][2]int) // area id -> super area index, area index
+	for _, area := range data.AllAreas() {
+		mappedArea := MappedMultilangArea{
+			AnkamaId:        area.Id,
+			Name:            multilangText(data, area.NameId),
+			SuperAreaId:     area.SuperAreaId,
+			ContainHouses:   area.ContainHouses,
+			ContainPaddocks: area.ContainPaddocks,
+			Bounds:          area.Bounds,
+			WorldmapId:      area.WorldmapId,
+			HasWorldMap:     area.HasWorldMap,
+		}
+		if _, found := data.LookupText("en", area.NameId); !found {
+			errs = append(errs, newDataError("areas.json", area.Id, "nameId", ErrMissingReference))
+		}
+
+		idx, found := superAreaIdx[area.SuperAreaId]
+		if !found {
+			idx = len(mappedSuperAreas)
+			superAreaIdx[area.SuperAreaId] = idx
+			mappedSuperArea := MappedMultilangSuperArea{AnkamaId: area.SuperAreaId}
+			if superArea, found := data.SuperArea(area.SuperAreaId); found {
+				mappedSuperArea.Name = multilangText(data, superArea.NameId)
+				if _, found := data.LookupText("en", superArea.NameId); !found {
+					errs = append(errs, newDataError("super_areas.json", superArea.Id, "nameId", ErrMissingReference))
+				}
+			}
+			mappedSuperAreas = append(mappedSuperAreas, mappedSuperArea)
+		}
+		areaIdx[area.Id] = [2]int{idx, len(mappedSuperAreas[idx].Areas)}
+		mappedSuperAreas[idx].Areas = append(mappedSuperAreas[idx].Areas, mappedArea)
+	}
+
+	for _, subArea := range data.AllSubAreas() {
+		idx, found := areaIdx[subArea.AreaId]
+		if !found {
+			errs = append(errs, newDataError("sub_areas.json", subArea.Id, "areaId", fmt.Errorf("area %d: %w", subArea.AreaId, ErrMissingReference)))
+			continue
+		}
+		if _, found := data.LookupText("en", subArea.NameId); !found {
+			errs = append(errs, newDataError("sub_areas.json", subArea.Id, "nameId", ErrMissingReference))
+		}
+		area := &mappedSuperAreas[idx[0]].Areas[idx[1]]
+		area.SubAreas = append(area.SubAreas, MappedMultilangSubArea{
+			AnkamaId: subArea.Id,
+			Name:     multilangText(data, subArea.NameId),
+			Level:    subArea.Level,
+		})
+	}
+
+	slices.SortFunc(mappedSuperAreas, func(a, b MappedMultilangSuperArea) int {
+		return a.AnkamaId - b.AnkamaId
+	})
+	for _, superArea := range mappedSuperAreas {
+		slices.SortFunc(superArea.Areas, func(a, b MappedMultilangArea) int {
+			return a.AnkamaId - b.AnkamaId
+		})
+		for _, area := range superArea.Areas {
+			slices.SortFunc(area.SubAreas, func(a, b MappedMultilangSubArea) int {
+				return a.AnkamaId - b.AnkamaId
+			})
+		}
+	}
+
+	return mappedSuperAreas, errors.Join(errs...)
+}
+
+// SubAreaById returns the sub-area with id from superAreas, like the SubareaIds of a monster.
+func SubAreaById(superAreas []MappedMultilangSuperArea, id int) (MappedMultilangSubArea, bool) {
+	for _, superArea := range superAreas {
+		for _, area := range superArea.Areas {
+			for _, subArea := range area.SubAreas {
+				if subArea.AnkamaId == id {
+					return subArea, true
+				}
+			}
+		}
+	}
+	return MappedMultilangSubArea{}, false
+}
+
+// AreaAt returns the area of superAreas whose bounds contain the world map coordinate x, y on worldmapId,
+// like the Coords of a quest objective. The smallest area wins when bounds overlap.
+func AreaAt(superAreas []MappedMultilangSuperArea, worldmapId int, x int, y int) (MappedMultilangArea, bool) {
+	var best MappedMultilangArea
+	found := false
+	for _, superArea := range superAreas {
+		for _, area := range superArea.Areas {
+			bounds := area.Bounds
+			if area.WorldmapId != worldmapId || x < bounds.X || x >= bounds.X+bounds.Width || y < bounds.Y || y >= bounds.Y+bounds.Height {
+				continue
+			}
+			if !found || bounds.Width*bounds.Height < best.Bounds.Width*best.Bounds.Height {
+				best = area
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+func MapNPCs(data *JSONGameData, langs *map[string]LangDict) ([]MappedMultilangNPC, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapNPCs(NewGameData(data, *langs))
+}
+
+// mapNPCs resolves the names and dialog texts of all npcs. Dialog entries start with the message or reply id
+// and text id. Message entries can list the ids of the replies that answer them after that, which become
+// ReplyIds; reply ids that no reply of the npc has are reported.
+func mapNPCs(data GameData) ([]MappedMultilangNPC, error) {
+	var errs []error
+	var mappedNPCs []MappedMultilangNPC
+	for _, npc := range data.AllNPCs() {
+		mappedNPC := MappedMultilangNPC{
+			AnkamaId: npc.Id,
+			Name:     multilangText(data, npc.NameId),
+			Actions:  npc.Actions,
+		}
+
+		dialogs := func(field string, entries [][]int, withReplies bool) []MappedMultilangNPCDialog {
+			var mappedDialogs []MappedMultilangNPCDialog
+			for _, entry := range entries {
+				if len(entry) < 2 {
+					errs = append(errs, newDataError("npcs.json", npc.Id, field, fmt.Errorf("dialog with %d values", len(entry))))
+					continue
+				}
+				if _, found := data.LookupText("en", entry[1]); !found {
+					errs = append(errs, newDataError("npcs.json", npc.Id, field, fmt.Errorf("text %d: %w", entry[1], ErrMissingReference)))
+				}
+				mappedDialog := MappedMultilangNPCDialog{Id: entry[0], Text: multilangText(data, entry[1])}
+				if withReplies && len(entry) > 2 {
+					mappedDialog.ReplyIds = entry[2:]
+				}
+				mappedDialogs = append(mappedDialogs, mappedDialog)
+			}
+			return mappedDialogs
+		}
+		mappedNPC.Messages = dialogs("dialogMessages", npc.DialogMessages, true)
+		mappedNPC.Replies = dialogs("dialogReplies", npc.DialogReplies, false)
+
+		replyIds := make(map[int]bool, len(mappedNPC.Replies))
+		for _, reply := range mappedNPC.Replies {
+			replyIds[reply.Id] = true
+		}
+		for _, message := range mappedNPC.Messages {
+			for _, replyId := range message.ReplyIds {
+				if !replyIds[replyId] {
+					errs = append(errs, newDataError("npcs.json", npc.Id, "dialogMessages", fmt.Errorf("message %d reply %d: %w", message.Id, replyId, ErrMissingReference)))
+				}
+			}
+		}
+
+		mappedNPCs = append(mappedNPCs, mappedNPC)
+	}
+
+	return mappedNPCs, errors.Join(errs...)
+}
+
+func MapMonsters(data *JSONGameData, langs *map[string]LangDict) ([]MappedMultilangMonster, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapMonsters(NewGameData(data, *langs))
+}
+
+func mapMonsters(data GameData) ([]MappedMultilangMonster, error) {
+	var errs []error
+	var mappedMonsters []MappedMultilangMonster
+	for _, monster := range data.AllMonsters() {
+		mappedMonster := MappedMultilangMonster{
+			AnkamaId:          monster.Id,
+			Name:              multilangText(data, monster.NameId),
+			IsBoss:            monster.IsBoss,
+			IsMiniBoss:        monster.IsMiniBoss,
+			IsQuestMonster:    monster.IsQuestMonster,
+			SubareaIds:        monster.Subareas,
+			FavoriteSubareaId: monster.FavoriteSubareaId,
+		}
+
+		race, found := data.MonsterRace(monster.Race)
+		if !found {
+			errs = append(errs, newDataError("monsters.json", monster.Id, "race", fmt.Errorf("race %d: %w", monster.Race, ErrMissingReference)))
+		}
+		mappedMonster.Race = MappedMultilangMonsterRace{
+			Id:          monster.Race,
+			Name:        multilangText(data, race.NameId),
+			SuperRaceId: race.SuperRaceId,
+		}
+
+		for _, grade := range monster.Grades {
+			mappedMonster.Grades = append(mappedMonster.Grades, MappedMonsterGrade{
+				Grade:             grade.Grade,
+				Level:             grade.Level,
+				LifePoints:        grade.LifePoints,
+				ActionPoints:      grade.ActionPoints,
+				MovementPoints:    grade.MovementPoints,
+				Vitality:          grade.Vitality,
+				Wisdom:            grade.Wisdom,
+				Strength:          grade.Strength,
+				Intelligence:      grade.Intelligence,
+				Chance:            grade.Chance,
+				Agility:           grade.Agility,
+				ApDodge:           grade.PaDodge,
+				MpDodge:           grade.PmDodge,
+				EarthResistance:   grade.EarthResistance,
+				AirResistance:     grade.AirResistance,
+				FireResistance:    grade.FireResistance,
+				WaterResistance:   grade.WaterResistance,
+				NeutralResistance: grade.NeutralResistance,
+				Experience:        grade.GradeXp,
+			})
+		}
+		slices.SortFunc(mappedMonster.Grades, func(a, b MappedMonsterGrade) int {
+			return a.Grade - b.Grade
+		})
+
+		for _, drop := range monster.Drops {
+			rates := []float64{drop.PercentDropForGrade1, drop.PercentDropForGrade2, drop.PercentDropForGrade3, drop.PercentDropForGrade4, drop.PercentDropForGrade5}
+			if len(monster.Grades) < len(rates) {
+				rates = rates[:len(monster.Grades)]
+			}
+			if _, found := data.Item(drop.ObjectId); !found {
+				errs = append(errs, newDataError("monsters.json", monster.Id, "drops", fmt.Errorf("item %d: %w", drop.ObjectId, ErrMissingReference)))
+			}
+			mappedMonster.Drops = append(mappedMonster.Drops, MappedMonsterDrop{
+				ItemId:        drop.ObjectId,
+				Count:         drop.Count,
+				Rates:         rates,
+				HasConditions: drop.HasCriteria,
+			})
+		}
+
+		mappedMonsters = append(mappedMonsters, mappedMonster)
+	}
+
+	return mappedMonsters, errors.Join(errs...)
+}
+
+// DropIndex returns the ids of the monsters that drop an item by item id, ascending. Other than the
+// DropMonsterIds of items, it is built from the drop tables of the monsters.
+func DropIndex(monsters []MappedMultilangMonster) map[int][]int {
+	index := make(map[int][]int)
+	for _, monster := range monsters {
+		for _, drop := range monster.Drops {
+			if !slices.Contains(index[drop.ItemId], monster.AnkamaId) {
+				index[drop.ItemId] = append(index[drop.ItemId], monster.AnkamaId)
+			}
+		}
+	}
+	for _, monsterIds := range index {
+		slices.Sort(monsterIds)
+	}
+	return index
+}
+
+func MapJobs(data *JSONGameData, langs *map[string]LangDict) ([]MappedMultilangJob, error) {
+	if data == nil || langs == nil {
+		return nil, ErrNilInput
+	}
+
+	return mapJobs(NewGameData(data, *langs))
+}
+
+// mapJobs returns the jobs with the skills that belong to them, both ordered by id.
+func mapJobs(data GameData) ([]MappedMultilangJob, error) {
+	var errs []error
+	var mappedJobs []MappedMultilangJob
+	jobIdx := make(map[int]int)
+	for _, job := range data.AllJobs() {
+		jobIdx[job.Id] = len(mappedJobs)
+		mappedJobs = append(mappedJobs, MappedMultilangJob{
+			AnkamaId: job.Id,
+			Name:     multilangText(data, job.NameId),
+			IconId:   job.IconId,
+		})
+	}
+
+	for _, skill := range data.AllSkills() {
+		idx, found := jobIdx[skill.ParentJobId]
+		if !found {
+			errs = append(errs, newDataError("skills.json", skill.Id, "parentJobId", fmt.Errorf("job %d: %w", skill.ParentJobId, ErrMissingReference)))
+			continue
 		}
+		mappedJobs[idx].Skills = append(mappedJobs[idx].Skills, MappedMultilangSkill{
+			AnkamaId:              skill.Id,
+			Name:                  multilangText(data, skill.NameId),
+			LevelMin:              skill.LevelMin,
+			IsForgemagus:          skill.IsForgemagus,
+			GatheredItemId:        skill.GatheredRessourceItem,
+			CraftableItemIds:      skill.CraftableItemIds,
+			ModifiableItemTypeIds: skill.ModifiableItemTypeIds,
+		})
 	}
 
-	return mappedItems
+	return mappedJobs, errors.Join(errs...)
 }
@@ -1,16 +1,29 @@
 package dodumap
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 )
 
 var TestingLangs map[string]LangDictUnity
 var TestingData *JSONGameDataUnity
+var TestingIds *PersistedIds
 
 func TestMain(m *testing.M) {
 	path, err := os.Getwd()
@@ -18,9 +31,15 @@ func TestMain(m *testing.M) {
 		log.Fatal(err)
 	}
 	dataPath := filepath.Join(path, "data")
-	TestingLangs = ParseRawLanguagesUnity(dataPath)
-	TestingData = ParseRawDataUnity(dataPath)
-	err = LoadPersistedElements(filepath.Join(path, "persistent"), "main", 3)
+	TestingLangs, err = ParseRawLanguagesUnity(dataPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	TestingData, err = ParseRawDataUnity(dataPath)
+	if err != nil {
+		log.Println(err) // partial data is fine for the tests
+	}
+	TestingIds, err = LoadPersistedElements(filepath.Join(path, "persistent"), "main", 3)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -36,6 +55,28 @@ func TestMain(m *testing.M) {
 	m.Run()
 }
 
+// testLangs has the same texts in every language of Languages.
+func testLangs(texts map[int]string) map[string]LangDict {
+	langs := make(map[string]LangDict)
+	for _, lang := range Languages {
+		langs[lang] = LangDict{Texts: texts}
+	}
+	return langs
+}
+
+// testLangsUnity has the same texts in every language of LanguagesUnity.
+func testLangsUnity(texts map[int]string) map[string]LangDictUnity {
+	langs := make(map[string]LangDictUnity)
+	for _, lang := range LanguagesUnity {
+		langs[lang] = LangDictUnity{Texts: texts}
+	}
+	return langs
+}
+
+func testIds() *PersistedIds {
+	return &PersistedIds{Elements: NewIDRegistry(), Types: NewIDRegistry()}
+}
+
 // TODO not up-to-date anymore with unity?
 // func TestParseSigness1(t *testing.T) {
 // 	num, side := ParseSignessUnity("-#1{~1~2 und -}#2")
@@ -141,7 +182,10 @@ func printTreeToString(node *ConditionTreeNodeMapped, level int) string {
 }
 
 func TestParseConditionSimple(t *testing.T) {
-	conditionTree := ParseConditionUnity("cs<25", &TestingLangs, TestingData)
+	conditionTree, err := ParseConditionUnity("cs<25", &TestingLangs, TestingData, TestingIds.Elements)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	depth := conditionTreeDepth(conditionTree)
 	if depth != 1 {
@@ -164,7 +208,10 @@ func TestParseConditionSimple(t *testing.T) {
 
 func TestParseConditionMulti(t *testing.T) {
 	toParse := "CS>80&CV>40&CA>40"
-	conditionTree := ParseConditionUnity(toParse, &TestingLangs, TestingData)
+	conditionTree, err := ParseConditionUnity(toParse, &TestingLangs, TestingData, TestingIds.Elements)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if conditionTree == nil {
 		t.Errorf("empty tree")
@@ -193,7 +240,10 @@ func TestParseConditionMulti(t *testing.T) {
 
 func TestParseConditionLimboWand(t *testing.T) {
 	toParse := "CP<12&CM<6&CW>99"
-	conditionTree := ParseConditionUnity(toParse, &TestingLangs, TestingData)
+	conditionTree, err := ParseConditionUnity(toParse, &TestingLangs, TestingData, TestingIds.Elements)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if conditionTree == nil {
 		t.Errorf("empty tree")
@@ -222,7 +272,10 @@ func TestParseConditionLimboWand(t *testing.T) {
 
 func TestParseOrAndConditionMulti(t *testing.T) {
 	toParse := "CS>80&(CV>40|CA>40)"
-	conditionTree := ParseConditionUnity(toParse, &TestingLangs, TestingData)
+	conditionTree, err := ParseConditionUnity(toParse, &TestingLangs, TestingData, TestingIds.Elements)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	depth := conditionTreeDepth(conditionTree)
 	if depth != 3 {
@@ -247,7 +300,10 @@ func TestParseOrAndConditionMulti(t *testing.T) {
 
 func TestParseAndConditionUnknowns(t *testing.T) {
 	toParse := "KEINE>80&JAU>40"
-	conditionTree := ParseConditionUnity(toParse, &TestingLangs, TestingData)
+	conditionTree, err := ParseConditionUnity(toParse, &TestingLangs, TestingData, TestingIds.Elements)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if conditionTree != nil {
 		t.Errorf("conditionTree is not nil: %s", printTreeToString(conditionTree, 0))
@@ -256,7 +312,10 @@ func TestParseAndConditionUnknowns(t *testing.T) {
 
 func TestParseConditionEmpty(t *testing.T) {
 	toParse := "null"
-	conditionTree := ParseConditionUnity(toParse, &TestingLangs, TestingData)
+	conditionTree, err := ParseConditionUnity(toParse, &TestingLangs, TestingData, TestingIds.Elements)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if conditionTree != nil {
 		t.Errorf("conditionTree should be empty with condition \"null\"")
@@ -386,6 +445,23 @@ func TestParseNumSpellNameFormatterLearnSpellLevel(t *testing.T) {
 	}
 }
 
+func TestParseNumSpellNameFormatterWithoutFrenchSignedness(t *testing.T) {
+	input := "Stufe #3 des Zauberspruchs erlernen"
+	diceNum := 0
+	diceSide := 0
+	value := 1746
+	frNumSigned := 2 // unset, the french template was not formatted
+	frSideSigned := 2
+	output, _ := NumSpellFormatterUnity(input, "de", TestingData, &TestingLangs, &diceNum, &diceSide, &value, 0, false, false, &frNumSigned, &frSideSigned)
+
+	if output != "Stufe 1746 des Zauberspruchs erlernen" {
+		t.Errorf("output is not as expected: %s", output)
+	}
+	if frNumSigned != 2 || frSideSigned != 2 {
+		t.Errorf("german changed the french signedness: %d %d", frNumSigned, frSideSigned)
+	}
+}
+
 func TestParseNumSpellNameFormatterLearnSpellLevel1(t *testing.T) {
 	input := "Stufe #3 des Zauberspruchs erlernen"
 	diceNum := 0
@@ -520,3 +596,1134 @@ func TestParseNumSpellNameFormatterMissingWhite(t *testing.T) {
 		t.Errorf("output is not as expected: %s", output)
 	}
 }
+
+func TestParseLangDictUnityFromPatchedArchive(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		"dump/languages/en.json": `{"entries": {"1": "Bow", "2": "Sword"}}`,
+		"dump/languages/fr.json": `{"entries": {"1": "Arc"}}`,
+	}
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archiveFS, err := TarGzFS(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dumpFS, err := fs.Sub(archiveFS, "dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched := OverlayFS{
+		fstest.MapFS{"languages/en.json": {Data: []byte(`{"entries": {"1": "Patched Bow"}}`)}},
+		dumpFS,
+	}
+
+	en, err := ParseLangDictUnityFS("en", patched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if en.Texts[1] != "Patched Bow" || len(en.Texts) != 1 {
+		t.Errorf("en is not patched: %v", en.Texts)
+	}
+
+	fr, err := ParseLangDictUnityFS("fr", patched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fr.Texts[1] != "Arc" {
+		t.Errorf("fr is not read from the archive: %v", fr.Texts)
+	}
+
+	_, err = ParseLangDictUnityFS("de", patched)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing language is not reported as fs.ErrNotExist: %v", err)
+	}
+}
+
+func TestLoadPersistedElementsFallsBackToCache(t *testing.T) {
+	requests := 0
+	notModified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		switch r.URL.Path {
+		case "/elements.dofus3.main.json":
+			fmt.Fprint(w, `["Air damage", "Wisdom"]`)
+		case "/item_types.dofus3.main.json":
+			fmt.Fprint(w, `["Bow"]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	fetcher := &HTTPRegistryFetcher{BaseURL: server.URL}
+	cacheDir := t.TempDir()
+	ctx := context.Background()
+
+	if _, err := LoadPersistedElementsWith(ctx, fetcher, cacheDir, "dofus3", 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPersistedElementsWith(ctx, fetcher, cacheDir, "dofus3", 3); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 4 || notModified != 2 {
+		t.Errorf("expected 2 conditional requests out of 4, got %d out of %d", notModified, requests)
+	}
+
+	server.Close()
+	ids, err := LoadPersistedElementsWith(ctx, fetcher, cacheDir, "dofus3", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, found := ids.Elements.Lookup("Wisdom"); !found || id != 1 {
+		t.Errorf("Wisdom is not loaded from the cache: %d", id)
+	}
+	if ids.Types.Len() != 1 {
+		t.Errorf("item types are not loaded from the cache: %d", ids.Types.Len())
+	}
+
+	if err := os.WriteFile(filepath.Join(cacheDir, "item_types.dofus3.main.json"), []byte(`["Sword"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPersistedElementsWith(ctx, fetcher, cacheDir, "dofus3", 3); err == nil {
+		t.Error("corrupt cache was used while offline")
+	}
+}
+
+func TestIDRegistryConcurrentAssignAndSave(t *testing.T) {
+	registry := NewIDRegistry()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				registry.GetOrAssign(fmt.Sprintf("element %d", (i+j)%50))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if registry.Len() != 50 {
+		t.Fatalf("expected 50 ids, got %d", registry.Len())
+	}
+
+	var saved bytes.Buffer
+	if err := registry.Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewIDRegistry()
+	if err := loaded.Load(&saved); err != nil {
+		t.Fatal(err)
+	}
+	for id := range 50 {
+		name, _ := registry.Name(id)
+		loadedId, found := loaded.Lookup(name)
+		if !found || loadedId != id {
+			t.Errorf("%s changed its id from %d to %d", name, id, loadedId)
+		}
+	}
+	if id, created := loaded.GetOrAssign("element 0"); created || id >= 50 {
+		t.Errorf("known name got a new id %d", id)
+	}
+	if id, created := loaded.GetOrAssign("new element"); !created || id != 50 {
+		t.Errorf("new name got id %d", id)
+	}
+}
+
+func TestIDRegistryAliasesAndRenames(t *testing.T) {
+	registry := newIDRegistryFrom([]string{"Air Damage", "Wisdom", "Critical hits"})
+	registry.AddAlias("Crits", "Critical hits")
+
+	if id, created := registry.GetOrAssign("air  damage"); created || id != 0 {
+		t.Errorf("case and whitespace change got id %d", id)
+	}
+	if id, created := registry.GetOrAssign("crits"); created || id != 2 {
+		t.Errorf("alias got id %d", id)
+	}
+	if len(registry.PossibleRenames()) != 0 {
+		t.Errorf("unexpected renames: %v", registry.PossibleRenames())
+	}
+
+	if id, created := registry.GetOrAssign("Wisdom Points"); !created || id != 3 {
+		t.Errorf("unrelated name got id %d", id)
+	}
+	id, created := registry.GetOrAssign("Critical hit")
+	if !created {
+		t.Fatalf("rename got the known id %d", id)
+	}
+	renames := registry.PossibleRenames()
+	if len(renames) != 1 || renames[0].Id != id || renames[0].SimilarId != 2 {
+		t.Errorf("rename is not reported: %v", renames)
+	}
+}
+
+func TestIDRegistryVersionMetadata(t *testing.T) {
+	registry := NewIDRegistry()
+	if err := registry.Load(strings.NewReader(`["Vitality", "Wisdom"]`)); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.BeginRun("3.0.1")
+	registry.GetOrAssign("Vitality")
+	registry.GetOrAssign("Vitality")
+	registry.BeginRun("3.1.0")
+	registry.GetOrAssign("Vitality")
+	pumpkin, _ := registry.GetOrAssign("Pumpkin")
+
+	var saved bytes.Buffer
+	if err := registry.Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewIDRegistry()
+	if err := loaded.Load(&saved); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int]RegistryEntry{
+		0:       {Name: "Vitality", FirstSeen: "3.0.1", LastSeen: "3.1.0", Occurrences: 1},
+		1:       {Name: "Wisdom"},
+		pumpkin: {Name: "Pumpkin", FirstSeen: "3.1.0", LastSeen: "3.1.0", Occurrences: 1},
+	}
+	for id, want := range expected {
+		if got, _ := loaded.Entry(id); got != want {
+			t.Errorf("entry %d is %+v, expected %+v", id, got, want)
+		}
+	}
+}
+
+func TestMapItemsSameForBothGameVersions(t *testing.T) {
+	texts := map[int]string{100: "Wooden Hammer", 101: "Hammer", 501945: "Strength"}
+	langsUnity := testLangsUnity(texts)
+	langs := testLangs(texts)
+
+	dataUnity := &JSONGameDataUnity{
+		Items:     map[int]JSONGameItemUnity{1: {Id: 1, TypeId: 7, NameId: 100, ItemSetId: -1, TwoHanded: 1, Criterions: "CS>50"}},
+		ItemTypes: map[int]JSONGameItemTypeUnity{7: {Id: 7, NameId: 101}},
+	}
+	data := &JSONGameData{
+		Items:     map[int]JSONGameItem{1: {Id: 1, TypeId: 7, NameId: 100, ItemSetId: -1, TwoHanded: true, Criteria: "CS>50"}},
+		ItemTypes: map[int]JSONGameItemType{7: {Id: 7, NameId: 101}},
+	}
+
+	itemsUnity, err := MapItemsUnity(dataUnity, &langsUnity, testIds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := MapItems(data, &langs, testIds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(itemsUnity) != 1 || len(items) != 1 {
+		t.Fatalf("mapped %d unity and %d items", len(itemsUnity), len(items))
+	}
+
+	if !itemsUnity[0].TwoHanded || !items[0].TwoHanded {
+		t.Error("two handed is lost")
+	}
+	if itemsUnity[0].ConditionTree == nil || itemsUnity[0].ConditionTree.Value.Templated["de"] != "Strength" {
+		t.Errorf("unity condition tree is %+v", itemsUnity[0].ConditionTree)
+	}
+	if len(items[0].Conditions) != 1 || items[0].Conditions[0].Value != 50 || items[0].ConditionTree == nil {
+		t.Errorf("conditions are %+v", items[0].Conditions)
+	}
+
+	// italian is the only language dofus 2 has and unity has not
+	for _, condition := range items[0].Conditions {
+		delete(condition.Templated, "it")
+	}
+	var dropItalian func(node *ConditionTreeNodeMapped)
+	dropItalian = func(node *ConditionTreeNodeMapped) {
+		if node.Value != nil {
+			delete(node.Value.Templated, "it")
+		}
+		for _, child := range node.Children {
+			dropItalian(child)
+		}
+	}
+	dropItalian(items[0].ConditionTree)
+	if !reflect.DeepEqual(itemsUnity[0].Conditions, items[0].Conditions) {
+		t.Errorf("conditions differ: %+v and %+v", itemsUnity[0].Conditions, items[0].Conditions)
+	}
+	if !reflect.DeepEqual(itemsUnity[0].ConditionTree, items[0].ConditionTree) {
+		t.Errorf("condition trees differ: %+v and %+v", itemsUnity[0].ConditionTree, items[0].ConditionTree)
+	}
+	if itemsUnity[0].Type.Name["fr"] != items[0].Type.Name["fr"] {
+		t.Errorf("type names differ: %q and %q", itemsUnity[0].Type.Name["fr"], items[0].Type.Name["fr"])
+	}
+}
+
+func TestQueryQuestSteps(t *testing.T) {
+	data := &JSONGameDataUnity{
+		quests: map[int]JSONGameQuestUnity{
+			5: {Id: 5, StepIds: JSONGameUnityAnkamaIdArray{Array: []int{30, 99, 10}}},
+		},
+		questSteps: map[int]JSONGameQuestStepUnity{
+			10: {Id: 10, QuestId: 5},
+			30: {Id: 30, QuestId: 5},
+			20: {Id: 20},
+		},
+	}
+
+	steps := data.QuestStepsOf(5)
+	if len(steps) != 2 || steps[0].Id != 30 || steps[1].Id != 10 {
+		t.Errorf("steps are %+v", steps)
+	}
+	if steps := data.QuestStepsOf(6); steps != nil {
+		t.Errorf("unknown quest has steps %+v", steps)
+	}
+	if _, found := data.QuestStep(99); found {
+		t.Error("found a missing step")
+	}
+
+	var ids []int
+	for id := range data.AllQuestSteps() {
+		ids = append(ids, id)
+	}
+	if fmt.Sprint(ids) != "[10 20 30]" {
+		t.Errorf("steps are not ordered: %v", ids)
+	}
+}
+
+func TestMapSpellsOrdersLevelsAndReportsMissing(t *testing.T) {
+	langs := testLangs(map[int]string{1: "Pressure", 2: "Offensive", 3: "Off", 4: "Damage: #1"})
+	data := &JSONGameData{
+		spells:     map[int]JSONGameSpell{7: {Id: 7, NameId: 1, TypeId: 4, SpellLevels: []int{71, 70, 79}}},
+		spellTypes: map[int]JSONGameSpellType{4: {Id: 4, LongNameId: 2, ShortNameId: 3}},
+		spellLevels: map[int]JSONGameSpellLevel{
+			70: {Id: 70, SpellId: 7, Grade: 1, ApCost: 3, MinCastInterval: 2},
+			71: {Id: 71, SpellId: 7, Grade: 2, ApCost: 2, Effects: []*JSONGameItemPossibleEffect{{EffectId: 100, MinimumValue: 5}}},
+		},
+		effects: map[int]JSONGameEffect{100: {Id: 100, DescriptionId: 4}},
+	}
+	ids := testIds()
+	spells, err := MapSpells(data, &langs, ids)
+	if !errors.Is(err, ErrMissingReference) {
+		t.Errorf("missing level is not reported: %v", err)
+	}
+	if len(spells) != 1 {
+		t.Fatalf("mapped %d spells", len(spells))
+	}
+	spell := spells[0]
+	if spell.Type.LongName["en"] != "Offensive" || spell.Type.ShortName["en"] != "Off" {
+		t.Errorf("spell type is %+v", spell.Type)
+	}
+	if len(spell.Levels) != 2 || spell.Levels[0].Grade != 1 || spell.Levels[1].Grade != 2 {
+		t.Fatalf("levels are %+v", spell.Levels)
+	}
+	if spell.Levels[0].ApCost != 3 || spell.Levels[0].Cooldown != 2 {
+		t.Errorf("first level is %+v", spell.Levels[0])
+	}
+	if effects := spell.Levels[1].Effects; len(effects) != 1 || effects[0].ElementId != -1 {
+		t.Errorf("second level effects are %+v", effects)
+	}
+	if ids.Elements.Len() != 0 {
+		t.Errorf("spell effects were added to the element registry: %d", ids.Elements.Len())
+	}
+}
+
+func TestMapBreedsUnityResolvesStringIds(t *testing.T) {
+	langs := testLangsUnity(map[int]string{10: "Iop", 11: "Iops", 12: "Strength and courage", 13: "Heal", 14: "Wooden Dofus"})
+	breed := JSONGameBreedUnity{Id: 8, ShortNameId: "10", LongNameId: "11", GameplayClassDescriptionId: "broken", GuideItemId: 99, BreedSpellsId: JSONGameUnityAnkamaIdArray{Array: []int{1, 2}}}
+	breed.BreedRolesId.Array = []JSONGameBreedRole{{RoleId: 2, Value: 1, Order: 2, DescriptionId: 13}, {RoleId: 1, Value: 3, Order: 1, DescriptionId: 12}}
+	data := &JSONGameDataUnity{
+		classes: map[int]JSONGameBreedUnity{8: breed},
+		Items:   map[int]JSONGameItemUnity{99: {Id: 99, NameId: 14}},
+	}
+
+	breeds, err := MapBreedsUnity(data, &langs)
+	var dataErr *DataError
+	if !errors.As(err, &dataErr) || dataErr.Field != "gameplayClassDescriptionId" {
+		t.Errorf("invalid text id is not reported: %v", err)
+	}
+	if len(breeds) != 1 {
+		t.Fatalf("mapped %d breeds", len(breeds))
+	}
+	iop := breeds[0]
+	if iop.ShortName["fr"] != "Iop" || iop.LongName["fr"] != "Iops" {
+		t.Errorf("names are %v and %v", iop.ShortName, iop.LongName)
+	}
+	if len(iop.Roles) != 2 || iop.Roles[0].RoleId != 1 || iop.Roles[0].Description["en"] != "Strength and courage" {
+		t.Errorf("roles are %+v", iop.Roles)
+	}
+	if iop.GuideItem == nil || iop.GuideItem.Name["de"] != "Wooden Dofus" {
+		t.Errorf("guide item is %+v", iop.GuideItem)
+	}
+}
+
+func TestMapQuestsKeepsStepOrderAndRewards(t *testing.T) {
+	langs := testLangs(map[int]string{1: "The Wooden Dofus", 2: "Find it", 3: "Bring it back", 4: "Dofus"})
+	data := &JSONGameData{
+		quests: map[int]JSONGameQuest{
+			5: {Id: 5, NameId: 1, StepIds: []int{30, 10}, CategoryId: 2, LevelMin: 10, LevelMax: 50, IsDungeonQuest: true},
+		},
+		questCategories: map[int]JSONGameQuestCategory{2: {Id: 2, NameId: 4, Order: 3}},
+		questSteps: map[int]JSONGameQuestStep{
+			10: {Id: 10, NameId: 3, OptimalLevel: 40, Duration: 1, ObjectiveIds: []int{100, 101}, RewardsIds: []int{200}},
+			30: {Id: 30, NameId: 2, OptimalLevel: 20, Duration: 0.5},
+		},
+		questObjectives: map[int]JSONGameQuestObjective{
+			100: {Id: 100, TypeId: 3, Parameters: JSONGameQuestParameter{NumParams: 2, Parameter0: 7, Parameter1: 1, Parameter2: 9}},
+		},
+		questStepRewards: map[int]JSONGameQuestStepRewards{
+			200: {Id: 200, KamasRatio: 1, ExperienceRatio: 1, LevelMax: 30, ItemsReward: [][]int{{44, 2}}},
+		},
+	}
+
+	quests, err := MapQuests(data, &langs, testIds())
+	if !errors.Is(err, ErrMissingReference) {
+		t.Errorf("missing objective is not reported: %v", err)
+	}
+	if len(quests) != 1 {
+		t.Fatalf("mapped %d quests", len(quests))
+	}
+	quest := quests[0]
+	if quest.Name["en"] != "The Wooden Dofus" || quest.Category.Name["en"] != "Dofus" || quest.Category.Order != 3 || !quest.IsDungeonQuest {
+		t.Errorf("quest is %+v", quest)
+	}
+	if len(quest.Steps) != 2 || quest.Steps[0].AnkamaId != 30 || quest.Steps[1].AnkamaId != 10 {
+		t.Fatalf("steps are %+v", quest.Steps)
+	}
+	step := quest.Steps[1]
+	if len(step.Objectives) != 1 || fmt.Sprint(step.Objectives[0].Parameters) != "[7 1]" {
+		t.Errorf("objectives are %+v", step.Objectives)
+	}
+	if len(step.Rewards) != 1 {
+		t.Fatalf("rewards are %+v", step.Rewards)
+	}
+	reward := step.Rewards[0]
+	if reward.Kamas != questKamasReward(30, 40, 1, 1, false) || reward.Experience != questExperienceReward(30, 30, 1, 1) {
+		t.Errorf("reward is %+v", reward)
+	}
+	if len(reward.Items) != 1 || reward.Items[0].ItemId != 44 || reward.Items[0].Quantity != 2 {
+		t.Errorf("item rewards are %+v", reward.Items)
+	}
+	if kamas := questKamasReward(-1, 10, 1, 1, true); kamas != 280 {
+		t.Errorf("scaling kamas without a max level are %d", kamas)
+	}
+}
+
+func TestParseQuestConditionUnity(t *testing.T) {
+	langs := testLangsUnity(map[int]string{1: "The Wooden Dofus", 2: "Iop", 3: "Farmer"})
+	data := &JSONGameDataUnity{
+		quests:  map[int]JSONGameQuestUnity{5: {Id: 5, NameId: 1}},
+		classes: map[int]JSONGameBreedUnity{8: {Id: 8, ShortNameId: "2"}},
+		jobs:    map[int]JSONGameJob{2: {Id: 2, NameId: 3}},
+	}
+	elements := NewIDRegistry()
+
+	tree, err := ParseQuestConditionUnity("Qf=5&(PG=8|PJ>2,40)&PX=1", &langs, data, elements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree == nil || tree.IsOperand || len(tree.Children) != 2 {
+		t.Fatalf("tree is %+v", tree)
+	}
+	quest := tree.Children[0].Value
+	if quest.Element != "Qf" || quest.Kind != ConditionKindQuestCompleted || quest.Value != 5 || quest.Templated["en"] != "Have completed the quest The Wooden Dofus" {
+		t.Errorf("quest condition is %+v", quest)
+	}
+	or := tree.Children[1]
+	if *or.Relation != "or" || len(or.Children) != 2 {
+		t.Fatalf("or is %+v", or)
+	}
+	if breed := or.Children[0].Value; breed.Templated["fr"] != "Classe : Iop" || breed.Subject["fr"] != "Iop" {
+		t.Errorf("breed condition is %+v", breed)
+	}
+	if job := or.Children[1].Value; job.Value != 40 || fmt.Sprint(job.Parameters) != "[2 40]" || job.Templated["en"] != "Farmer level ≥ 41" || job.Subject["de"] != "Farmer" {
+		t.Errorf("job condition is %+v", job)
+	}
+
+	if tree, _ := ParseConditionUnity("Qf=5", &langs, data, elements); tree != nil {
+		t.Errorf("single item condition was kept: %+v", tree)
+	}
+	if tree, _ := ParseConditionUnity("Qf=5&PG=8", &langs, data, elements); tree == nil || len(tree.Children) != 2 {
+		t.Errorf("item conditions do not know quest criteria: %+v", tree)
+	}
+	if quest.ElementId != conditionKindElementIds[ConditionKindQuestCompleted] || elements.Len() != 0 {
+		t.Errorf("conditions without game texts got element id %d and %d registered names", quest.ElementId, elements.Len())
+	}
+}
+
+func TestQuestGraphChainsAndCycles(t *testing.T) {
+	data := &JSONGameData{
+		quests: map[int]JSONGameQuest{
+			1: {Id: 1},
+			2: {Id: 2, StartCriterion: "Qf=1"},
+			3: {Id: 3, StartCriterion: "Qf=1&PL>10"},
+			4: {Id: 4, StartCriterion: "Qf=2&(Qf=3|Qf=7)&Qf=99"},
+			5: {Id: 5, StartCriterion: "Qf=6"},
+			6: {Id: 6, StartCriterion: "Qf=5"},
+			7: {Id: 7, StartCriterion: "Qf=8|Qf=1"},
+			8: {Id: 8, StartCriterion: "Qf=7|Qf=1"},
+		},
+	}
+
+	graph, err := NewQuestGraph(data)
+	if !errors.Is(err, ErrMissingReference) {
+		t.Errorf("unknown quest is not reported: %v", err)
+	}
+	if fmt.Sprint(graph.Prerequisites(4)) != "[2]" || fmt.Sprint(graph.Unlocks(1)) != "[2 3]" {
+		t.Errorf("prerequisites %v, unlocks %v", graph.Prerequisites(4), graph.Unlocks(1))
+	}
+	if fmt.Sprint(graph.Alternatives(4)) != "[3 7]" || fmt.Sprint(graph.Alternatives(7)) != "[1 8]" || len(graph.Prerequisites(7)) != 0 {
+		t.Errorf("alternatives %v and %v, prerequisites %v", graph.Alternatives(4), graph.Alternatives(7), graph.Prerequisites(7))
+	}
+
+	chain, err := graph.Chain(4)
+	if err != nil || fmt.Sprint(chain) != "[1 2 4]" {
+		t.Errorf("chain is %v: %v", chain, err)
+	}
+	if chain, err := graph.Chain(8); err != nil || fmt.Sprint(chain) != "[8]" {
+		t.Errorf("chain over alternatives is %v: %v", chain, err)
+	}
+	if _, err := graph.Chain(5); !errors.Is(err, ErrQuestCycle) {
+		t.Errorf("cycle is not reported: %v", err)
+	}
+	if cycles := graph.Cycles(); fmt.Sprint(cycles) != "[[5 6]]" {
+		t.Errorf("cycles are %v", cycles)
+	}
+	if order, err := graph.Order(); !errors.Is(err, ErrQuestCycle) || fmt.Sprint(order) != "[1 2 3 4 7 8]" {
+		t.Errorf("order is %v: %v", order, err)
+	}
+
+	var mermaid strings.Builder
+	graph.WriteMermaid(&mermaid, func(questId int) string { return fmt.Sprintf("Quest \"%d\"", questId) })
+	if !strings.Contains(mermaid.String(), "q1[\"Quest #quot;1#quot;\"]") || !strings.Contains(mermaid.String(), "q2 --> q4") || !strings.Contains(mermaid.String(), "q3 -.-> q4") {
+		t.Errorf("mermaid is\n%s", mermaid.String())
+	}
+	var dot strings.Builder
+	graph.WriteDOT(&dot, nil)
+	if !strings.Contains(dot.String(), "q1 [label=\"1\"];") || !strings.Contains(dot.String(), "q2 -> q4;") || !strings.Contains(dot.String(), "q7 -> q4 [style=dashed];") {
+		t.Errorf("dot is\n%s", dot.String())
+	}
+}
+
+func TestParseEffectsUnityTitleReference(t *testing.T) {
+	langs := testLangsUnity(map[int]string{50: "Title: #3", 60: "Explorer", 61: "Exploress"})
+	data := &JSONGameDataUnity{
+		effects: map[int]JSONGameEffectUnity{100: {Id: 100, DescriptionId: 50}},
+		titles:  map[int]JSONGameTitleUnity{7: {Id: 7, NameMaleId: "60", NameFemaleId: "61", Visible: 1}},
+	}
+
+	effects, err := ParseEffectsUnity(data, [][]*JSONGameItemPossibleEffectUnity{{{EffectId: 100, MinimumValue: 7}}}, &langs, NewIDRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(effects) != 1 || len(effects[0]) != 1 || effects[0][0] == nil {
+		t.Fatalf("effects are %+v", effects)
+	}
+	effect := effects[0][0]
+	if effect.Title == nil || effect.Title.AnkamaId != 7 || !effect.Title.Visible {
+		t.Fatalf("title is %+v", effect.Title)
+	}
+	if effect.Templated["en"] != "Title: Explorer" || effect.TemplatedFemale["en"] != "Title: Exploress" {
+		t.Errorf("templated %q and %q", effect.Templated["en"], effect.TemplatedFemale["en"])
+	}
+
+	titles, err := MapTitlesUnity(data, &langs)
+	if err != nil || len(titles) != 1 || titles[0].NameFemale["fr"] != "Exploress" {
+		t.Errorf("titles are %+v: %v", titles, err)
+	}
+}
+
+func TestMapAreasAndAreaAt(t *testing.T) {
+	langs := testLangs(map[int]string{1: "Amakna", 2: "Amakna Village", 3: "Astrub", 4: "The World of Twelve", 5: "Crackler Mountain"})
+	data := &JSONGameData{
+		superAreas: map[int]JSONGameSuperArea{0: {Id: 0, NameId: 4}},
+		subAreas: map[int]JSONGameSubArea{
+			100: {Id: 100, NameId: 5, AreaId: 10, Level: 20},
+			101: {Id: 101, NameId: 5, AreaId: 77},
+		},
+		areas: map[int]JSONGameArea{
+			10: {Id: 10, NameId: 1, SuperAreaId: 0, WorldmapId: 1, Bounds: JSONGameAreaBounds{X: -20, Y: -20, Width: 40, Height: 40}},
+			11: {Id: 11, NameId: 2, SuperAreaId: 0, WorldmapId: 1, Bounds: JSONGameAreaBounds{X: -5, Y: -5, Width: 10, Height: 10}},
+			12: {Id: 12, NameId: 3, SuperAreaId: 3, WorldmapId: 2, Bounds: JSONGameAreaBounds{X: 0, Y: 0, Width: 5, Height: 5}},
+			13: {Id: 13, NameId: 99, SuperAreaId: 3},
+		},
+	}
+
+	superAreas, err := MapAreas(data, &langs)
+	if !errors.Is(err, ErrMissingReference) {
+		t.Errorf("missing name is not reported: %v", err)
+	}
+	if len(superAreas) != 2 || superAreas[0].AnkamaId != 0 || len(superAreas[0].Areas) != 2 || len(superAreas[1].Areas) != 2 {
+		t.Fatalf("super areas are %+v", superAreas)
+	}
+	if superAreas[0].Name["en"] != "The World of Twelve" || superAreas[1].Name != nil {
+		t.Errorf("super area names are %v and %v", superAreas[0].Name, superAreas[1].Name)
+	}
+	if subArea, found := SubAreaById(superAreas, 100); !found || subArea.Name["fr"] != "Crackler Mountain" || len(superAreas[0].Areas[0].SubAreas) != 1 {
+		t.Errorf("sub-area is %+v in %+v", subArea, superAreas[0].Areas[0])
+	}
+	if !strings.Contains(err.Error(), "area 77") {
+		t.Errorf("sub-area of a missing area is not reported: %v", err)
+	}
+
+	if area, found := AreaAt(superAreas, 1, 0, 0); !found || area.Name["en"] != "Amakna Village" {
+		t.Errorf("smallest area is not found: %+v", area)
+	}
+	if area, found := AreaAt(superAreas, 1, 10, -20); !found || area.AnkamaId != 10 {
+		t.Errorf("outer area is not found: %+v", area)
+	}
+	if area, found := AreaAt(superAreas, 2, 10, 10); found {
+		t.Errorf("found %+v outside of all bounds", area)
+	}
+}
+
+func TestMapAreasOrderedById(t *testing.T) {
+	langs := testLangs(map[int]string{1: "Area"})
+	data := &JSONGameData{
+		subAreas: map[int]JSONGameSubArea{
+			302: {Id: 302, NameId: 1, AreaId: 30},
+			201: {Id: 201, NameId: 1, AreaId: 20},
+			301: {Id: 301, NameId: 1, AreaId: 30},
+			300: {Id: 300, NameId: 1, AreaId: 30},
+		},
+		areas: map[int]JSONGameArea{
+			30: {Id: 30, NameId: 1, SuperAreaId: 5},
+			21: {Id: 21, NameId: 1, SuperAreaId: 2},
+			20: {Id: 20, NameId: 1, SuperAreaId: 2},
+			31: {Id: 31, NameId: 1, SuperAreaId: 5},
+		},
+	}
+
+	superAreas, err := MapAreas(data, &langs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, superArea := range superAreas {
+		ids = append(ids, superArea.AnkamaId)
+		for _, area := range superArea.Areas {
+			ids = append(ids, area.AnkamaId)
+			for _, subArea := range area.SubAreas {
+				ids = append(ids, subArea.AnkamaId)
+			}
+		}
+	}
+	if fmt.Sprint(ids) != "[2 20 201 21 5 30 300 301 302 31]" {
+		t.Errorf("areas are ordered %v", ids)
+	}
+}
+
+func TestMapNPCsUnityResolvesDialogs(t *testing.T) {
+	langs := testLangsUnity(map[int]string{1: "Antyklime Ax", 2: "Welcome!", 3: "Goodbye."})
+	var npc JSONGameNPCUnity
+	err := json.Unmarshal([]byte(`{"id": 4, "nameId": 1, "dialogMessages": {"Array": [{"values": {"Array": [10, 2, 20]}}, {"values": {"Array": [11, 2, 22]}}]},
+		"dialogReplies": {"Array": [{"values": {"Array": [20, 3]}}, {"values": {"Array": [21]}}]}, "actions": {"Array": [3, 1]}}`), &npc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := &JSONGameDataUnity{npcs: map[int]JSONGameNPCUnity{4: npc}}
+
+	npcs, err := MapNPCsUnity(data, &langs)
+	var dataErr *DataError
+	if !errors.As(err, &dataErr) || dataErr.Field != "dialogReplies" {
+		t.Errorf("short reply is not reported: %v", err)
+	}
+	if !errors.Is(err, ErrMissingReference) || !strings.Contains(err.Error(), "reply 22") {
+		t.Errorf("reply of a message is not checked: %v", err)
+	}
+	if len(npcs) != 1 {
+		t.Fatalf("mapped %d npcs", len(npcs))
+	}
+	mapped := npcs[0]
+	if mapped.Name["de"] != "Antyklime Ax" || fmt.Sprint(mapped.Actions) != "[3 1]" {
+		t.Errorf("npc is %+v", mapped)
+	}
+	if len(mapped.Messages) != 2 || mapped.Messages[0].Id != 10 || mapped.Messages[0].Text["en"] != "Welcome!" || fmt.Sprint(mapped.Messages[0].ReplyIds) != "[20]" {
+		t.Errorf("messages are %+v", mapped.Messages)
+	}
+	if len(mapped.Replies) != 1 || mapped.Replies[0].Text["pt"] != "Goodbye." || mapped.Replies[0].ReplyIds != nil {
+		t.Errorf("replies are %+v", mapped.Replies)
+	}
+}
+
+func TestMapMonstersUnityFromRawData(t *testing.T) {
+	fsys := fstest.MapFS{
+		"monsters.json": {Data: []byte(`{"references": {"version": 2, "RefIds": [
+			{"rid": "1", "type": {"class": "MonsterData"}, "data": {"id": 31, "nameId": 1, "race": 4, "isBoss": 0,
+				"grades": {"Array": [{"grade": 2, "level": 3, "lifePoints": 20}, {"grade": 1, "level": 1, "lifePoints": 10}]},
+				"drops": {"Array": [{"objectId": 287, "count": 1, "percentDropForGrade1": 10, "percentDropForGrade2": 12, "hasCriteria": 1}]},
+				"subareas": {"Array": [95]}}},
+			{"rid": "2", "type": {"class": "MonsterData"}, "data": {"id": 30, "nameId": 2, "race": 4,
+				"drops": {"Array": [{"objectId": 287, "count": 1}, {"objectId": 288, "count": 2}]}}}]}}`)},
+		"monster_races.json": {Data: []byte(`{"references": {"version": 2, "RefIds": [
+			{"rid": "1", "type": {"class": "MonsterRace"}, "data": {"id": 4, "superRaceId": 1, "nameId": 3, "monsters": {"Array": [30, 31]}}}]}}`)},
+	}
+	data, _ := ParseRawDataUnityFS(fsys) // the other files are missing
+	data.Items = map[int]JSONGameItemUnity{287: {Id: 287}}
+
+	langs := testLangsUnity(map[int]string{1: "Tofu", 2: "Black Tofu", 3: "Tofus"})
+
+	monsters, err := MapMonstersUnity(data, &langs)
+	if !errors.Is(err, ErrMissingReference) {
+		t.Errorf("unknown drop is not reported: %v", err)
+	}
+	if len(monsters) != 2 || monsters[0].AnkamaId != 30 {
+		t.Fatalf("monsters are %+v", monsters)
+	}
+	tofu := monsters[1]
+	if tofu.Name["en"] != "Tofu" || tofu.Race.Name["fr"] != "Tofus" || fmt.Sprint(tofu.SubareaIds) != "[95]" {
+		t.Errorf("tofu is %+v", tofu)
+	}
+	if len(tofu.Grades) != 2 || tofu.Grades[0].Grade != 1 || tofu.Grades[1].LifePoints != 20 {
+		t.Errorf("grades are %+v", tofu.Grades)
+	}
+	if len(tofu.Drops) != 1 || fmt.Sprint(tofu.Drops[0].Rates) != "[10 12]" || !tofu.Drops[0].HasConditions {
+		t.Errorf("drops are %+v", tofu.Drops)
+	}
+
+	index := DropIndex(monsters)
+	if fmt.Sprint(index[287]) != "[30 31]" || fmt.Sprint(index[288]) != "[30]" {
+		t.Errorf("drop index is %v", index)
+	}
+}
+
+func TestMapJobsAndRecipeJobs(t *testing.T) {
+	langs := testLangs(map[int]string{1: "Lumberjack", 2: "Cut", 3: "Craft a board"})
+	data := &JSONGameData{
+		jobs: map[int]JSONGameJob{2: {Id: 2, NameId: 1}},
+		skills: map[int]JSONGameSkill{
+			6:  {Id: 6, NameId: 2, ParentJobId: 2, GatheredRessourceItem: 303},
+			71: {Id: 71, NameId: 3, ParentJobId: 2, GatheredRessourceItem: -1, CraftableItemIds: []int{2539}},
+			99: {Id: 99, ParentJobId: 7},
+		},
+		Recipes: map[int]JSONGameRecipe{2539: {Id: 2539, Level: 10, JobId: 2, SkillId: 71, IngredientIds: []int{303}, Quantities: []int{10}}},
+	}
+
+	jobs, err := MapJobs(data, &langs)
+	if !errors.Is(err, ErrMissingReference) {
+		t.Errorf("skill of an unknown job is not reported: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Name["en"] != "Lumberjack" || len(jobs[0].Skills) != 2 {
+		t.Fatalf("jobs are %+v", jobs)
+	}
+	if skill := jobs[0].Skills[1]; skill.Name["fr"] != "Craft a board" || skill.GatheredItemId != -1 || fmt.Sprint(skill.CraftableItemIds) != "[2539]" {
+		t.Errorf("skill is %+v", skill)
+	}
+
+	recipes, err := MapRecipes(data)
+	if err != nil || len(recipes) != 1 {
+		t.Fatalf("recipes are %+v: %v", recipes, err)
+	}
+	if recipes[0].JobId != 2 || recipes[0].SkillId != 71 || recipes[0].Level != 10 {
+		t.Errorf("recipe is %+v", recipes[0])
+	}
+}
+
+func TestRecipeBookExpand(t *testing.T) {
+	book := NewRecipeBook([]MappedMultilangRecipe{
+		{ResultId: 1, JobId: 10, Level: 50, Entries: []MappedMultilangRecipeEntry{{ItemId: 2, Quantity: 2}, {ItemId: 3, Quantity: 1}}},
+		{ResultId: 2, JobId: 11, Level: 20, Entries: []MappedMultilangRecipeEntry{{ItemId: 3, Quantity: 5}, {ItemId: 4, Quantity: 1}}},
+		{ResultId: 5, JobId: 10, Level: 1, Entries: []MappedMultilangRecipeEntry{{ItemId: 6, Quantity: 1}}},
+		{ResultId: 6, JobId: 10, Level: 1, Entries: []MappedMultilangRecipeEntry{{ItemId: 5, Quantity: 1}}},
+	})
+
+	expansion, err := book.Expand(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(expansion.Resources) != "[{3 33} {4 6}]" {
+		t.Errorf("resources are %v", expansion.Resources)
+	}
+	if fmt.Sprint(expansion.Jobs) != "[{10 50} {11 20}]" {
+		t.Errorf("jobs are %v", expansion.Jobs)
+	}
+	if len(expansion.Tree.Ingredients) != 2 || expansion.Tree.Ingredients[0].Quantity != 6 || !expansion.Tree.Ingredients[0].Craftable {
+		t.Errorf("tree is %+v", expansion.Tree)
+	}
+
+	if _, err := book.Expand(5, 1); !errors.Is(err, ErrRecipeCycle) {
+		t.Errorf("cycle is not reported: %v", err)
+	}
+}
+
+func TestRecipeBookUsedIn(t *testing.T) {
+	book := NewRecipeBook([]MappedMultilangRecipe{
+		{ResultId: 1, JobId: 10, Level: 50, Entries: []MappedMultilangRecipeEntry{{ItemId: 2, Quantity: 2}, {ItemId: 3, Quantity: 1}}},
+		{ResultId: 2, JobId: 11, Level: 20, Entries: []MappedMultilangRecipeEntry{{ItemId: 3, Quantity: 5}, {ItemId: 4, Quantity: 1}}},
+		{ResultId: 7, JobId: 12, Level: 5, Entries: []MappedMultilangRecipeEntry{{ItemId: 8, Quantity: 1}, {ItemId: 9, Quantity: 1}}},
+		{ResultId: 8, JobId: 12, Level: 5, Entries: []MappedMultilangRecipeEntry{{ItemId: 7, Quantity: 1}}},
+	})
+
+	usages, err := book.UsedIn(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(usages) != "[{1 10 50 1 11} {2 11 20 1 5}]" {
+		t.Errorf("usages are %v", usages)
+	}
+
+	usages, err = book.UsedIn(3, 10)
+	if err != nil || len(usages) != 1 || usages[0].ItemId != 1 {
+		t.Errorf("usages for job 10 are %v: %v", usages, err)
+	}
+
+	usages, err = book.UsedIn(4)
+	if err != nil || len(usages) != 2 || usages[1].ItemId != 1 || usages[1].Depth != 2 || usages[1].Quantity != 2 {
+		t.Errorf("usages of 4 are %v: %v", usages, err)
+	}
+
+	if _, err := book.UsedIn(9); !errors.Is(err, ErrRecipeCycle) {
+		t.Errorf("cycle is not reported: %v", err)
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	condition := func(element string, operator string, value int) *ConditionTreeNodeMapped {
+		return &ConditionTreeNodeMapped{IsOperand: true, Value: &MappedMultilangCondition{Element: element, Operator: operator, Value: value}}
+	}
+	relation := func(relation string, children ...*ConditionTreeNodeMapped) *ConditionTreeNodeMapped {
+		return &ConditionTreeNodeMapped{Relation: &relation, Children: children}
+	}
+	// PL>49&(CS>200|Ca>200)&PX=1
+	tree := relation("and", condition("PL", ">", 49), relation("or", condition("CS", ">", 200), condition("Ca", ">", 200)), condition("PX", "=", 1))
+
+	profile := CharacterProfile{Level: 50, Base: Characteristics{Strength: 150, Agility: 150}, Bonus: Characteristics{Strength: 60, Agility: 100}}
+	evaluation := EvaluateCondition(tree, profile)
+	if evaluation.Result != ConditionUnknown || len(evaluation.Failed) != 0 || len(evaluation.Unknown) != 1 || evaluation.Unknown[0].Element != "PX" {
+		t.Errorf("evaluation is %+v", evaluation)
+	}
+
+	profile.Bonus.Strength = 0 // only the bonus agility, which does not count for "Ca"
+	evaluation = EvaluateCondition(tree, profile)
+	if evaluation.Result != ConditionFailed || len(evaluation.Failed) != 2 {
+		t.Errorf("evaluation without strength is %+v", evaluation)
+	}
+
+	if evaluation := EvaluateCondition(relation("or", condition("PX", "=", 1), condition("PZ", "=", 0)), profile); evaluation.Result != ConditionPassed {
+		t.Errorf("or with an unknown is %v", evaluation.Result)
+	}
+	if evaluation := EvaluateCondition(condition("Ps", "=", 1), profile); evaluation.Result != ConditionFailed {
+		t.Errorf("mount equipped without mount is %v", evaluation.Result)
+	}
+	profile.MountId, profile.MountFamilyId = 7, 2
+	if evaluation := EvaluateCondition(relation("and", condition("Pf", "=", 7), condition("Of", "=", 2), condition("Ps", "=", 1)), profile); evaluation.Result != ConditionPassed {
+		t.Errorf("mount and its family are %+v", evaluation)
+	}
+	if evaluation := EvaluateCondition(nil, profile); evaluation.Result != ConditionPassed {
+		t.Errorf("no condition is %v", evaluation.Result)
+	}
+}
+
+func expressionString(node *ConditionTreeNode) string {
+	if node == nil || node.Type == Operand {
+		if node == nil {
+			return "<nil>"
+		}
+		return node.Value
+	}
+	parts := make([]string, len(node.Children))
+	for i, child := range node.Children {
+		parts[i] = expressionString(child)
+	}
+	return node.Value + "(" + strings.Join(parts, " ") + ")"
+}
+
+func TestParseExpressionPrecedenceAndErrors(t *testing.T) {
+	valid := map[string]string{
+		"a|b&c":         "|(a &(b c))",
+		"a&b|c":         "|(&(a b) c)",
+		"a&b&c":         "&(&(a b) c)",
+		"(a|b)&c":       "&(|(a b) c)",
+		" PL>5 & Qf=1 ": "&(PL>5 Qf=1)",
+		"PG=1,2":        "PG=1,2",
+		"CS > 10|PG= 1": "|(CS>10 PG=1)",
+	}
+	for exp, want := range valid {
+		tree, err := parseExpression(exp)
+		if err != nil || expressionString(tree) != want {
+			t.Errorf("%q parsed to %s, %v, want %s", exp, expressionString(tree), err, want)
+		}
+	}
+
+	invalid := map[string]int{
+		"(a&b":  4,
+		"a&":    2,
+		"a||b":  2,
+		"a)":    1,
+		"&a":    0,
+		"(a)(b": 3,
+		"a b":   2,
+	}
+	for exp, position := range invalid {
+		_, err := parseExpression(exp)
+		var expErr *ExpressionError
+		if !errors.As(err, &expErr) || expErr.Position != position {
+			t.Errorf("%q gave %v, want an error at %d", exp, err, position)
+		}
+	}
+
+	langs := map[string]LangDict{}
+	_, _, err := ParseCondition("PL>5&", &langs, &JSONGameData{}, NewIDRegistry())
+	var expErr *ExpressionError
+	if !errors.As(err, &expErr) {
+		t.Error("parseCondition accepted a dangling operator")
+	}
+}
+
+func FuzzParseExpression(f *testing.F) {
+	for _, seed := range []string{"PL>5&(CS>10|CA>10)", "a|b&c", "((a)", "a&|b", "Qf=1,2&PG!3"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, exp string) {
+		tree, err := parseExpression(exp)
+		if err != nil {
+			return
+		}
+		var check func(node *ConditionTreeNode)
+		check = func(node *ConditionTreeNode) {
+			switch node.Type {
+			case Operand:
+				if node.Value == "" || strings.ContainsAny(node.Value, "&|() \t\n") || len(node.Children) != 0 {
+					t.Fatalf("%q has a bad operand %q", exp, node.Value)
+				}
+			case Operator:
+				if len(node.Children) != 2 {
+					t.Fatalf("%q has an operator with %d children", exp, len(node.Children))
+				}
+				check(node.Children[0])
+				check(node.Children[1])
+			}
+		}
+		if tree != nil {
+			check(tree)
+		}
+	})
+}
+
+func TestParseConditionKeepUnknown(t *testing.T) {
+	langs := testLangs(map[int]string{1096588: "Level %1"})
+	data := &JSONGameData{}
+	elements := NewIDRegistry()
+
+	conditions, tree, err := ParseCondition("PL>5|PX=0", &langs, data, elements)
+	if err != nil || tree == nil || !tree.IsOperand || len(conditions) != 1 {
+		t.Fatalf("default parse removed the wrong parts: %+v, %+v, %v", conditions, tree, err)
+	}
+
+	conditions, tree, err = ParseConditionKeepUnknown("PL>5|PX=0,3", &langs, data, elements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree == nil || tree.IsOperand || *tree.Relation != "or" || len(tree.Children) != 2 {
+		t.Fatalf("tree is %+v", tree)
+	}
+	raw := tree.Children[1].Value
+	if !raw.Raw || raw.Element != "PX" || raw.Operator != "=" || raw.Value != 0 || fmt.Sprint(raw.Parameters) != "[0 3]" || raw.Criterion != "PX=0,3" {
+		t.Errorf("raw condition is %+v", raw)
+	}
+	if len(conditions) != 0 {
+		t.Errorf("or-connected conditions in the flat list: %+v", conditions)
+	}
+	if evaluation := EvaluateCondition(tree, CharacterProfile{Level: 1}); evaluation.Result != ConditionUnknown {
+		t.Errorf("raw condition evaluated to %v", evaluation.Result)
+	}
+}
+
+func TestConditionKindsFromCatalogue(t *testing.T) {
+	langs := testLangsUnity(map[int]string{1096588: "Be level {0} or higher", 1092470: "Different area to: {0}", 637203: "Have no %1 mount equipped",
+		3: "Amakna", 4: "Dofus Ocre", 5: "Crackler Mountain", 6: "Dofus Ocre Quest"})
+	data := &JSONGameDataUnity{
+		areas:        map[int]JSONGameAreaUnity{7: {Id: 7, NameId: 3}},
+		subAreas:     map[int]JSONGameSubArea{8: {Id: 8, NameId: 5, AreaId: 7}},
+		achievements: map[int]JSONGameAchievement{9: {Id: 9, NameId: 6}},
+		Items:        map[int]JSONGameItemUnity{44: {Id: 44, NameId: 4}},
+	}
+
+	tree, err := ParseConditionKeepUnknownUnity("PL>49&Po!7&PO=44&PS=1&Ps=0&PB=8&OA=9&OA=10", &langs, data, NewIDRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conditions []*MappedMultilangCondition
+	var collect func(node *ConditionTreeNodeMapped)
+	collect = func(node *ConditionTreeNodeMapped) {
+		if node.IsOperand {
+			conditions = append(conditions, node.Value)
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(tree)
+
+	want := []struct {
+		kind      ConditionKind
+		valueKind ConditionValueKind
+		templated string
+	}{
+		{ConditionKindLevel, ConditionValueLevel, "Be level 50 or higher"},
+		{ConditionKindArea, ConditionValueAreaId, "Different area to: Amakna"},
+		{ConditionKindItemInInventory, ConditionValueItemId, "Have Dofus Ocre"},
+		{ConditionKindGender, ConditionValueGender, ""}, // no game texts, kept raw
+		{ConditionKindMountEquipped, ConditionValueBool, "Have no mount equipped"},
+		{ConditionKindSubArea, ConditionValueSubAreaId, "Be in the subarea Crackler Mountain"},
+		{ConditionKindAchievement, ConditionValueAchievementId, "Have unlocked the achievement Dofus Ocre Quest"},
+		{ConditionKindAchievement, ConditionValueAchievementId, ""}, // unknown achievement, kept raw
+	}
+	if len(conditions) != len(want) {
+		t.Fatalf("got %d conditions, want %d", len(conditions), len(want))
+	}
+	for i, condition := range conditions {
+		if condition.Kind != want[i].kind || len(condition.ValueKinds) != 1 || condition.ValueKinds[0] != want[i].valueKind || condition.Templated["en"] != want[i].templated {
+			t.Errorf("condition %d is %v %v %q", i, condition.Kind, condition.ValueKinds, condition.Templated["en"])
+		}
+	}
+
+	if conditions[1].Subject["en"] != "Amakna" {
+		t.Errorf("area subject is %v", conditions[1].Subject)
+	}
+
+	kindJson, _ := json.Marshal(conditions[2].Kind)
+	if string(kindJson) != `"item_in_inventory"` {
+		t.Errorf("kind json is %s", kindJson)
+	}
+}
+
+func TestRenderCondition(t *testing.T) {
+	condition := func(element string, kind ConditionKind, operator string, value int, templated string) *ConditionTreeNodeMapped {
+		return &ConditionTreeNodeMapped{IsOperand: true, Value: &MappedMultilangCondition{Element: element, Kind: kind, Operator: operator, Value: value, Templated: map[string]string{"en": templated, "fr": templated}}}
+	}
+	relation := func(relation string, children ...*ConditionTreeNodeMapped) *ConditionTreeNodeMapped {
+		return &ConditionTreeNodeMapped{Relation: &relation, Children: children}
+	}
+	raw := &ConditionTreeNodeMapped{IsOperand: true, Value: &MappedMultilangCondition{Raw: true, Criterion: "PX<1"}}
+	// (PL>49&(CS>200|CA>200))&PX<1, nested like the parser builds it
+	tree := relation("and",
+		relation("and", condition("PL", ConditionKindLevel, ">", 49, "Be level 50 or higher"),
+			relation("or", condition("CS", ConditionKindCharacteristic, ">", 200, "Strength"), condition("CA", ConditionKindCharacteristic, ">", 200, "Agility"))),
+		raw)
+
+	if text := RenderCondition(tree, "en", ConditionFormatText); text != "Level ≥ 50 and (Strength > 200 or Agility > 200) and PX<1" {
+		t.Errorf("text is %q", text)
+	}
+	if markdown := RenderCondition(tree, "fr", ConditionFormatMarkdown); markdown != "Niveau ≥ 50 _et_ (Strength > 200 _ou_ Agility > 200) _et_ `PX<1`" {
+		t.Errorf("markdown is %q", markdown)
+	}
+	if html := RenderCondition(tree, "en", ConditionFormatHTML); html != "Level ≥ 50 <em>and</em> (Strength &gt; 200 <em>or</em> Agility &gt; 200) <em>and</em> <code>PX&lt;1</code>" {
+		t.Errorf("html is %q", html)
+	}
+
+	negated := relation("or", condition("PG", ConditionKindBreed, "!", 8, "Class: Iop"), condition("PZ", ConditionKindSubscribed, "=", 1, "Be subscribed"))
+	if rendered := RenderConditionMultilang(negated, []string{"en"}, ConditionFormatText); rendered["en"] != "not Class: Iop or Be subscribed" {
+		t.Errorf("negated is %q", rendered["en"])
+	}
+	levels := relation("and", condition("PJ", ConditionKindJobLevel, ">", 40, "Paysan"), condition("Pa", ConditionKindAlignmentLevel, "<", 20, "Niveau d'alignement"),
+		condition("PG", ConditionKindBreed, "!", 8, "Iop"))
+	if text := RenderCondition(levels, "fr", ConditionFormatText); text != "Paysan Niveau ≥ 41 et Niveau d'alignement ≤ 19 et non Iop" {
+		t.Errorf("levels are %q", text)
+	}
+	quest := func(kind ConditionKind, operator string) *ConditionTreeNodeMapped {
+		node := condition("Qf", kind, operator, 5, "Have completed the quest The Wooden Dofus")
+		node.Value.Subject = map[string]string{"en": "The Wooden Dofus"}
+		return node
+	}
+	quests := relation("or", quest(ConditionKindQuestCompleted, "="), quest(ConditionKindQuestActive, "="), quest(ConditionKindQuestStartable, "!"))
+	if text := RenderCondition(quests, "en", ConditionFormatText); text != "Quest The Wooden Dofus completed or Quest The Wooden Dofus in progress or Quest The Wooden Dofus can not be started" {
+		t.Errorf("quests are %q", text)
+	}
+	astrub := condition("Po", ConditionKindArea, "=", 8, "Zone différente de : Astrub")
+	astrub.Value.Subject = map[string]string{"fr": "Astrub"}
+	area := relation("and", condition("Po", ConditionKindArea, "!", 7, "Zone différente de : Amakna"), astrub)
+	if text := RenderCondition(area, "fr", ConditionFormatText); text != "Zone différente de : Amakna et Être dans la zone Astrub" {
+		t.Errorf("area is %q", text)
+	}
+	if text := RenderCondition(nil, "en", ConditionFormatText); text != "" {
+		t.Errorf("no condition is %q", text)
+	}
+}
+
+func TestParseConditionCountsElementsOnce(t *testing.T) {
+	langs := testLangs(map[int]string{501945: "Strength", 501941: "Agility"})
+	elements := NewIDRegistry()
+	elements.BeginRun("2.70")
+
+	_, tree, err := ParseCondition("CS>10&CA>5", &langs, &JSONGameData{}, elements)
+	if err != nil || tree == nil {
+		t.Fatalf("tree is %+v, %v", tree, err)
+	}
+	for _, name := range []string{"Strength", "Agility"} {
+		id, _ := elements.Lookup(name)
+		if entry, _ := elements.Entry(id); entry.Occurrences != 1 {
+			t.Errorf("%s has %d occurrences", name, entry.Occurrences)
+		}
+	}
+}
+
+func TestParseConditionFallsBackToEnglish(t *testing.T) {
+	langs := testLangs(map[int]string{501945: "Strength", 501941: "Agility"})
+	langs["fr"] = LangDict{Texts: map[int]string{501945: "Force"}}
+
+	_, tree, err := ParseCondition("CS>10&CA>5", &langs, &JSONGameData{}, NewIDRegistry())
+	if err != nil || tree == nil || len(tree.Children) != 2 {
+		t.Fatalf("tree is %+v, %v", tree, err)
+	}
+	if strength, agility := tree.Children[0].Value, tree.Children[1].Value; strength.Templated["fr"] != "Force" || agility.Templated["fr"] != "Agility" {
+		t.Errorf("french texts are %q and %q", strength.Templated["fr"], agility.Templated["fr"])
+	}
+}
+
+func TestParseRawDataWithoutOptionalFiles(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, name := range []string{"npcs.json", "mount_family.json", "breeds.json", "mounts.json", "areas.json", "spell_types.json",
+		"spells.json", "recipes.json", "items.json", "item_types.json", "item_sets.json", "bonuses.json", "effects.json", "titles.json",
+		"quests.json", "quest_objectives.json", "quest_step_rewards.json", "quest_categories.json", "almanax.json", "quest_steps.json"} {
+		fsys[name] = &fstest.MapFile{Data: []byte("[]")}
+	}
+	if _, err := ParseRawDataFS(fsys); err != nil {
+		t.Errorf("dump without the optional files failed: %v", err)
+	}
+
+	delete(fsys, "items.json")
+	_, err := ParseRawDataFS(fsys)
+	var dataErr *DataError
+	if !errors.As(err, &dataErr) || dataErr.File != "items.json" {
+		t.Errorf("missing items are not reported: %v", err)
+	}
+}
+
+func TestParseRawDataPartUnityMultiReportsBrokenReferences(t *testing.T) {
+	fsys := fstest.MapFS{
+		"item_sets.json": {Data: []byte(`{"references": {"version": 2, "RefIds": [
+			{"rid": "1", "type": {"class": "ItemSetData"}, "data": {"id": 3, "nameId": 1}},
+			{"rid": "2", "type": {"class": "EffectInstanceDice"}, "data": {"id": 7, "effectId": "broken"}},
+			{"rid": "3", "type": {"class": "EffectInstanceDice"}, "data": {"effectId": 118}}]}}`)},
+	}
+	effectType := "EffectInstanceDice"
+
+	lookup, err := ParseRawDataPartUnityMulti[JSONGameSetUnityRaw, JSONGameItemPossibleEffectUnity]("item_sets.json", fsys, "ItemSetData", &effectType)
+	var dataErr *DataError
+	if !errors.As(err, &dataErr) || dataErr.File != "item_sets.json" || dataErr.EntityId != 7 {
+		t.Errorf("broken effect is not reported: %v", err)
+	}
+	if _, found := lookup.Ref[2]; found {
+		t.Error("broken effect is kept")
+	}
+	if effect, found := lookup.Ref[3]; !found || effect.EffectId != 118 {
+		t.Errorf("effect is %+v", effect)
+	}
+	if _, found := lookup.AnkamaId[3]; !found {
+		t.Error("set is lost")
+	}
+}
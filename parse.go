@@ -1,35 +1,25 @@
 package dodumap
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"slices"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/log"
-	"github.com/emirpasic/gods/maps/treebidimap"
-	gutils "github.com/emirpasic/gods/utils"
 )
 
-type PersistentStringKeysMap struct {
-	Entries *treebidimap.Map `json:"entries"`
-	NextId  int              `json:"next_id"`
-}
-
-var (
-	PersistedElements PersistentStringKeysMap
-	PersistedTypes    PersistentStringKeysMap
-)
-
-func LoadPersistedElements(persistenceDir string, release string, majorVersion int) error {
-	var elements []string
-	var types []string
+// persistedFileNames returns the file names of the persisted elements and item types for a release.
+func persistedFileNames(release string, majorVersion int) (string, string) {
 	if release == "dofus3" {
 		release = "main"
 	}
@@ -37,114 +27,40 @@ func LoadPersistedElements(persistenceDir string, release string, majorVersion i
 	if majorVersion == 3 {
 		dofus3prefix = ".dofus3"
 	}
-	if persistenceDir == "" {
-		elementUrl := fmt.Sprintf("https://raw.githubusercontent.com/dofusdude/doduda/main/persistent/elements%s.%s.json", dofus3prefix, release)
-		elementResponse, err := http.Get(elementUrl)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		elementBody, err := io.ReadAll(elementResponse.Body)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		err = json.Unmarshal(elementBody, &elements)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		itemTypeUrl := fmt.Sprintf("https://raw.githubusercontent.com/dofusdude/doduda/main/persistent/item_types%s.%s.json", dofus3prefix, release)
-		itemTypeResponse, err := http.Get(itemTypeUrl)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		itemTypeBody, err := io.ReadAll(itemTypeResponse.Body)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		err = json.Unmarshal(itemTypeBody, &types)
-		if err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		data, err := os.ReadFile(filepath.Join(persistenceDir, fmt.Sprintf("elements%s.%s.json", dofus3prefix, release)))
-		if err != nil {
-			return err
-		}
-
-		err = json.Unmarshal(data, &elements)
-		if err != nil {
-			fmt.Println(err)
-		}
-
-		data, err = os.ReadFile(filepath.Join(persistenceDir, fmt.Sprintf("item_types%s.%s.json", dofus3prefix, release)))
-		if err != nil {
-			return err
-		}
-
-		err = json.Unmarshal(data, &types)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
-
-	PersistedElements = PersistentStringKeysMap{
-		Entries: treebidimap.NewWith(gutils.IntComparator, gutils.StringComparator),
-		NextId:  0,
-	}
-
-	for _, entry := range elements {
-		PersistedElements.Entries.Put(PersistedElements.NextId, entry)
-		PersistedElements.NextId++
-	}
-
-	PersistedTypes = PersistentStringKeysMap{
-		Entries: treebidimap.NewWith(gutils.IntComparator, gutils.StringComparator),
-		NextId:  0,
-	}
-
-	for _, entry := range types {
-		PersistedTypes.Entries.Put(PersistedTypes.NextId, entry)
-		PersistedTypes.NextId++
-	}
-
-	return nil
+	return fmt.Sprintf("elements%s.%s.json", dofus3prefix, release), fmt.Sprintf("item_types%s.%s.json", dofus3prefix, release)
 }
 
-func PersistElements(elementPath string, itemTypePath string) error {
-	elements := make([]string, PersistedElements.NextId)
-	it := PersistedElements.Entries.Iterator()
-	for it.Next() {
-		elements[it.Key().(int)] = it.Value().(string)
+// LoadPersistedElements reads the persisted ids from persistenceDir. When persistenceDir is empty,
+// they are downloaded from DefaultRegistryURL and cached in DefaultRegistryCacheDir.
+func LoadPersistedElements(persistenceDir string, release string, majorVersion int) (*PersistedIds, error) {
+	if persistenceDir == "" {
+		fetcher := &HTTPRegistryFetcher{BaseURL: DefaultRegistryURL}
+		return LoadPersistedElementsWith(context.Background(), fetcher, DefaultRegistryCacheDir(), release, majorVersion)
 	}
 
-	elementsJson, err := json.MarshalIndent(elements, "", "    ")
+	elementsName, typesName := persistedFileNames(release, majorVersion)
+	data, err := os.ReadFile(filepath.Join(persistenceDir, elementsName))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = os.WriteFile(elementPath, elementsJson, 0644)
+	elements, err := decodeRegistryFile(elementsName, data)
 	if err != nil {
-		return err
-	}
-
-	types := make([]string, PersistedTypes.NextId)
-	it = PersistedTypes.Entries.Iterator()
-	for it.Next() {
-		types[it.Key().(int)] = it.Value().(string)
+		return nil, err
 	}
 
-	typesJson, err := json.MarshalIndent(types, "", "    ")
+	data, err = os.ReadFile(filepath.Join(persistenceDir, typesName))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = os.WriteFile(itemTypePath, typesJson, 0644)
+	types, err := decodeRegistryFile(typesName, data)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+
+	return &PersistedIds{
+		Elements: newIDRegistryFromEntries(elements),
+		Types:    newIDRegistryFromEntries(types),
+	}, nil
 }
 
 func ParseItemCombo(rawEffects [][]*JSONGameItemPossibleEffect, effects [][]MappedMultilangEffect) [][]MappedMultilangSetEffect {
@@ -183,35 +99,79 @@ func ParseItemCombo(rawEffects [][]*JSONGameItemPossibleEffect, effects [][]Mapp
 	return mappedEffects
 }
 
-func ParseEffects(data *JSONGameData, allEffects [][]*JSONGameItemPossibleEffect, langs *map[string]LangDict) [][]MappedMultilangEffect {
+func ParseEffects(data *JSONGameData, allEffects [][]*JSONGameItemPossibleEffect, langs *map[string]LangDict, elements *IDRegistry) ([][]MappedMultilangEffect, error) {
+	parsedEffects, err := parseEffects(NewGameData(data, *langs), allEffects, elements, true)
+	if err != nil {
+		return nil, err
+	}
+
 	var mappedAllEffects [][]MappedMultilangEffect
-	for _, effects := range allEffects {
+	for _, effects := range parsedEffects {
 		var mappedEffects []MappedMultilangEffect
+		for _, effect := range effects {
+			if effect != nil {
+				mappedEffects = append(mappedEffects, *effect)
+			}
+		}
+		if len(mappedEffects) > 0 {
+			mappedAllEffects = append(mappedAllEffects, mappedEffects)
+		}
+	}
+	if len(mappedAllEffects) == 0 {
+		return nil, nil
+	}
+	return mappedAllEffects, nil
+}
+
+// parseEffects maps all effect combinations. Effects that are missing or cannot be shown are nil,
+// so the result has the same shape as allEffects. New effect wordings only get an element id when
+// assignIds is set, otherwise they are -1.
+func parseEffects(data GameData, allEffects [][]*JSONGameItemPossibleEffect, elements *IDRegistry, assignIds bool) ([][]*MappedMultilangEffect, error) {
+	if elements == nil {
+		return nil, ErrRegistryNotLoaded
+	}
+
+	dialect := data.dialect()
+	var mappedAllEffects [][]*MappedMultilangEffect
+	for _, effects := range allEffects {
+		var mappedEffects []*MappedMultilangEffect
 		for _, effect := range effects {
 
 			if effect == nil {
+				mappedEffects = append(mappedEffects, nil)
 				continue
 			}
 
 			var mappedEffect MappedMultilangEffect
-			currentEffect := data.effects[effect.EffectId]
+			currentEffect, _ := data.Effect(effect.EffectId)
 
 			numIsSpell := false
-			if strings.Contains((*langs)["de"].Texts[currentEffect.DescriptionId], "Zauberspruchs #1") || strings.Contains((*langs)["de"].Texts[currentEffect.DescriptionId], "Zaubers #1") {
+			if strings.Contains(data.Text("de", currentEffect.DescriptionId), "Zauberspruchs #1") || strings.Contains(data.Text("de", currentEffect.DescriptionId), "Zaubers #1") {
 				numIsSpell = true
 			}
 
 			isTitle := false
-			if strings.Contains((*langs)["en"].Texts[currentEffect.DescriptionId], "Title:") {
+			if strings.Contains(data.Text("en", currentEffect.DescriptionId), "Title:") {
 				isTitle = true
 			}
 
 			mappedEffect.Type = make(map[string]string)
 			mappedEffect.Templated = make(map[string]string)
+			titleId := effect.MinimumValue
+			if isTitle {
+				mappedEffect.TemplatedFemale = make(map[string]string)
+				title, found := data.Title(titleId)
+				if found && title.NameMaleId >= 0 {
+					mappedTitle := mapTitle(data, title)
+					mappedEffect.Title = &mappedTitle
+				} else {
+					log.Warn("EffectParsing", "InvalidTitleId", titleId)
+				}
+			}
 			var minMaxRemove int
 			var frNumSigned int = 2  // unset
 			var frSideSigned int = 2 // unset
-			for _, lang := range Languages {
+			for _, lang := range data.Languages() {
 				var diceNum int
 				var diceSide int
 				var value int
@@ -222,7 +182,7 @@ func ParseEffects(data *JSONGameData, allEffects [][]*JSONGameItemPossibleEffect
 
 				value = effect.Value
 
-				effectName := (*langs)[lang].Texts[currentEffect.DescriptionId]
+				effectName := data.Text(lang, currentEffect.DescriptionId)
 				if lang == "de" {
 					effectName = strings.ReplaceAll(effectName, "{~ps}{~zs}", "") // german has error in template
 				}
@@ -232,22 +192,32 @@ func ParseEffects(data *JSONGameData, allEffects [][]*JSONGameItemPossibleEffect
 					mappedEffect.Min = 0
 					mappedEffect.Max = 0
 					mappedEffect.Type[lang] = effectName
-					mappedEffect.Templated[lang] = (*langs)[lang].Texts[data.spells[diceNum].DescriptionId]
+					spell, _ := data.Spell(diceNum)
+					mappedEffect.Templated[lang] = data.Text(lang, spell.DescriptionId)
 					mappedEffect.IsMeta = true
 				} else {
 					templatedName := effectName
-					templatedName, minMaxRemove = NumSpellFormatter(templatedName, lang, data, langs, &diceNum, &diceSide, &value, currentEffect.DescriptionId, numIsSpell, currentEffect.UseDice, &frNumSigned, &frSideSigned)
+					templatedName, minMaxRemove = numSpellFormatter(templatedName, lang, data, &diceNum, &diceSide, &value, currentEffect.DescriptionId, numIsSpell, currentEffect.UseDice, &frNumSigned, &frSideSigned)
 					if templatedName == "" { // found effect that should be discarded for now
 						break
 					}
-					templatedName = SingularPluralFormatter(templatedName, effect.MinimumValue, lang)
+					templatedName = dialect.singularPlural(templatedName, effect.MinimumValue, lang)
 
-					if isTitle { // titles are Title: 0 after formatting; TODO move this into the NumSpellFormatter
-						templatedName = strings.ReplaceAll(templatedName, "0", (*langs)[lang].Texts[data.titles[diceNum].NameMaleId]) // TODO male default, idk how to make it neutral yet
+					if isTitle {
+						maleName, femaleName := fmt.Sprint(titleId), fmt.Sprint(titleId)
+						if mappedEffect.Title != nil {
+							maleName, femaleName = mappedEffect.Title.NameMale[lang], mappedEffect.Title.NameFemale[lang]
+						}
+						templatedName = titleTemplated(data, effectName, maleName, lang)
+						mappedEffect.TemplatedFemale[lang] = titleTemplated(data, effectName, femaleName, lang)
 					}
 
-					effectName = DeleteDamageFormatter(effectName)
-					effectName = SingularPluralFormatter(effectName, effect.MinimumValue, lang)
+					effectName = dialect.deleteDamage(effectName)
+					if dialect.singularEffectNames {
+						effectName = dialect.singularPlural(effectName, 1, lang) // singularize the effect name for comparisons
+					} else {
+						effectName = dialect.singularPlural(effectName, effect.MinimumValue, lang)
+					}
 
 					if isTitle {
 						mappedEffect.Min = 0
@@ -269,6 +239,8 @@ func ParseEffects(data *JSONGameData, allEffects [][]*JSONGameItemPossibleEffect
 			}
 
 			if mappedEffect.Type["en"] == "()" || mappedEffect.Type["en"] == "" {
+				// this happens way too often but we can't do anything about it
+				mappedEffects = append(mappedEffects, nil)
 				continue
 			}
 
@@ -277,27 +249,36 @@ func ParseEffects(data *JSONGameData, allEffects [][]*JSONGameItemPossibleEffect
 			if mappedEffect.Active {
 				searchTypeEn += " (Active)"
 			}
-			key, foundKey := PersistedElements.Entries.GetKey(searchTypeEn)
-			if foundKey {
-				mappedEffect.ElementId = key.(int)
+			if assignIds {
+				mappedEffect.ElementId, _ = elements.GetOrAssign(searchTypeEn)
 			} else {
-				mappedEffect.ElementId = PersistedElements.NextId
-				PersistedElements.Entries.Put(PersistedElements.NextId, searchTypeEn)
-				PersistedElements.NextId++
+				mappedEffect.ElementId, _ = elements.Lookup(searchTypeEn)
 			}
 
 			mappedEffect.MinMaxIrrelevant = minMaxRemove
 
-			mappedEffects = append(mappedEffects, mappedEffect)
-		}
-		if len(mappedEffects) > 0 {
-			mappedAllEffects = append(mappedAllEffects, mappedEffects)
+			mappedEffects = append(mappedEffects, &mappedEffect)
 		}
+		mappedAllEffects = append(mappedAllEffects, mappedEffects)
 	}
 	if len(mappedAllEffects) == 0 {
-		return nil
+		return nil, nil
+	}
+	return mappedAllEffects, nil
+}
+
+var titlePlaceholderRegex = regexp.MustCompile(`#[1-3]`)
+
+// titleTemplated puts name in place of the value of a title effect description like "Title: #3".
+func titleTemplated(data GameData, description string, name string, lang string) string {
+	placeholder := titlePlaceholderRegex.FindStringIndex(description)
+	if placeholder == nil {
+		return description
 	}
-	return mappedAllEffects
+	const marker = "\x00"
+	templated := description[:placeholder[0]] + marker + description[placeholder[1]:]
+	templated = data.dialect().singularPlural(DeleteReplacer(templated), 1, lang)
+	return strings.TrimSpace(strings.Replace(templated, marker, name, 1))
 }
 
 // NewNode creates a new Node
@@ -314,62 +295,183 @@ func (n *ConditionTreeNode) AddChild(child *ConditionTreeNode) {
 	n.Children = append(n.Children, child)
 }
 
-func ParseExpression(exp string) *ConditionTreeNode {
-	var stack []*ConditionTreeNode
-	var current *ConditionTreeNode
-	var operandBuilder strings.Builder
+// ExpressionError is a syntax error in a criterion expression.
+type ExpressionError struct {
+	Expression string
+	Position   int // byte offset in Expression
+	Msg        string
+}
 
-	conditionOperators := []rune{'<', '>', '=', '!'}
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("criterion %q at position %d: %s", e.Expression, e.Position, e.Msg)
+}
 
-	for _, char := range exp {
-		if unicode.IsLetter(char) || unicode.IsDigit(char) || slices.Contains(conditionOperators, char) {
-			operandBuilder.WriteRune(char) // continue building operand
-		} else {
-			if operandBuilder.Len() > 0 {
-				// finalize and add the operand
-				if current != nil && current.Type == Operator {
-					current.AddChild(newNode(operandBuilder.String(), Operand))
-				} else {
-					current = newNode(operandBuilder.String(), Operand)
-				}
-				operandBuilder.Reset()
-			}
+type expressionTokenKind int
 
-			switch char {
-			case '(':
-				if current != nil {
-					stack = append(stack, current)
-				}
-				current = nil
-			case ')':
-				if len(stack) > 0 {
-					parent := stack[len(stack)-1]
-					stack = stack[:len(stack)-1]
-					parent.AddChild(current)
-					current = parent
-				}
-			case '&', '|': // expression operators
-				operator := newNode(string(char), Operator)
-				if current != nil {
-					operator.AddChild(current)
+const (
+	tokenOperand expressionTokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenOpen
+	tokenClose
+	tokenEnd
+)
+
+type expressionToken struct {
+	kind     expressionTokenKind
+	value    string
+	position int
+}
+
+// tokenizeExpression splits a criterion like "CS>10&(PL>5|PK=1)" into operands, operators and parentheses.
+// Whitespace around a comparison inside an operand like "CS > 10" is dropped, any other whitespace ends
+// the operand. Everything else belongs to an operand.
+func tokenizeExpression(exp string) []expressionToken {
+	var tokens []expressionToken
+	var operand strings.Builder
+	operandStart := -1
+	endOperand := func() {
+		if operandStart >= 0 {
+			tokens = append(tokens, expressionToken{tokenOperand, operand.String(), operandStart})
+			operand.Reset()
+			operandStart = -1
+		}
+	}
+	isComparison := func(char rune) bool { return strings.ContainsRune("<>=!", char) }
+	for i, char := range exp {
+		kind := tokenOperand
+		switch char {
+		case '&':
+			kind = tokenAnd
+		case '|':
+			kind = tokenOr
+		case '(':
+			kind = tokenOpen
+		case ')':
+			kind = tokenClose
+		}
+		if unicode.IsSpace(char) {
+			if operandStart >= 0 {
+				last, _ := utf8.DecodeLastRuneInString(operand.String())
+				next, _ := utf8.DecodeRuneInString(strings.TrimLeftFunc(exp[i:], unicode.IsSpace))
+				if isComparison(last) || isComparison(next) {
+					continue
 				}
-				current = operator
 			}
+			endOperand()
+			continue
+		}
+		if kind != tokenOperand {
+			endOperand()
+			tokens = append(tokens, expressionToken{kind, string(char), i})
+			continue
 		}
+		if operandStart < 0 {
+			operandStart = i
+		}
+		operand.WriteRune(char)
 	}
+	endOperand()
+	return append(tokens, expressionToken{tokenEnd, "", len(exp)})
+}
 
-	if operandBuilder.Len() > 0 {
-		if current != nil && current.Type == Operator {
-			current.AddChild(newNode(operandBuilder.String(), Operand))
-		} else {
-			current = newNode(operandBuilder.String(), Operand)
+// expressionParser is a recursive descent parser for
+//
+//	or      = and { "|" and }
+//	and     = primary { "&" primary }
+//	primary = operand | "(" or ")"
+//
+// so & binds stronger than |. Both are left associative.
+type expressionParser struct {
+	exp    string
+	tokens []expressionToken
+	pos    int
+}
+
+func (p *expressionParser) peek() expressionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) errorAt(token expressionToken, msg string) error {
+	return &ExpressionError{Expression: p.exp, Position: token.position, Msg: msg}
+}
+
+func (p *expressionParser) parseBinary(kind expressionTokenKind, next func() (*ConditionTreeNode, error)) (*ConditionTreeNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == kind {
+		operator := p.peek()
+		p.pos++
+		right, err := next()
+		if err != nil {
+			return nil, err
 		}
+		node := newNode(operator.value, Operator)
+		node.AddChild(left)
+		node.AddChild(right)
+		left = node
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseOr() (*ConditionTreeNode, error) {
+	return p.parseBinary(tokenOr, p.parseAnd)
+}
+
+func (p *expressionParser) parseAnd() (*ConditionTreeNode, error) {
+	return p.parseBinary(tokenAnd, p.parsePrimary)
+}
+
+func (p *expressionParser) parsePrimary() (*ConditionTreeNode, error) {
+	token := p.peek()
+	switch token.kind {
+	case tokenOperand:
+		p.pos++
+		return newNode(token.value, Operand), nil
+	case tokenOpen:
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenClose {
+			return nil, p.errorAt(p.peek(), fmt.Sprintf("missing \")\" for \"(\" at position %d", token.position))
+		}
+		p.pos++
+		return node, nil
+	case tokenEnd:
+		return nil, p.errorAt(token, "unexpected end, expected a condition")
+	}
+	return nil, p.errorAt(token, fmt.Sprintf("unexpected %q, expected a condition", token.value))
+}
+
+// parseExpression parses a criterion into a tree of binary & and | operators. An empty criterion has no
+// tree. Malformed criteria return an ExpressionError.
+func parseExpression(exp string) (*ConditionTreeNode, error) {
+	parser := &expressionParser{exp: exp, tokens: tokenizeExpression(exp)}
+	if parser.peek().kind == tokenEnd {
+		return nil, nil
+	}
+	tree, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tokenEnd {
+		return nil, parser.errorAt(token, fmt.Sprintf("unexpected %q after the condition", token.value))
 	}
+	return tree, nil
+}
 
-	return current
+// ParseExpression parses a criterion like "CS>10&(PL>5|PK=1)". It returns nil for malformed criteria,
+// use parseCondition to get the error.
+func ParseExpression(exp string) *ConditionTreeNode {
+	tree, _ := parseExpression(exp)
+	return tree
 }
 
-func atomicCondition(expression string, langs *map[string]LangDict, data *JSONGameData) (bool, MappedMultilangCondition) {
+func atomicCondition(expression string, data GameData, elements *IDRegistry) (bool, MappedMultilangCondition, error) {
 	operators := []string{"<", ">", "=", "!"}
 
 	var out MappedMultilangCondition
@@ -378,7 +480,10 @@ func atomicCondition(expression string, langs *map[string]LangDict, data *JSONGa
 	foundCond := false
 	for _, operator := range operators { // try every known operator against it
 		if strings.Contains(expression, operator) {
-			foundConditionElement := ConditionWithOperator(expression, operator, langs, &out, data)
+			foundConditionElement, err := conditionWithOperator(expression, operator, data, &out, elements)
+			if err != nil {
+				return false, out, err
+			}
 			if foundConditionElement {
 				foundCond = true
 				break
@@ -386,31 +491,68 @@ func atomicCondition(expression string, langs *map[string]LangDict, data *JSONGa
 		}
 	}
 
-	return foundCond, out
+	return foundCond, out, nil
 }
 
-func removeUnsupportedExpressions(node *ConditionTreeNode, langs *map[string]LangDict, data *JSONGameData) *ConditionTreeNode {
+// rawCondition splits an unknown criterion like "PX=0" at its first operator. Criteria without an
+// operator are kept completely as Element.
+func rawCondition(expression string) MappedMultilangCondition {
+	out := MappedMultilangCondition{Element: expression, Raw: true, Criterion: expression}
+	operatorIdx := strings.IndexAny(expression, "<>=!~")
+	if operatorIdx == -1 {
+		return out
+	}
+	out.Element = expression[:operatorIdx]
+	out.Operator = expression[operatorIdx : operatorIdx+1]
+	values := strings.Split(expression[operatorIdx+1:], ",")
+	out.Value, _ = strconv.Atoi(values[0])
+	if len(values) > 1 {
+		out.Parameters = make([]int, len(values))
+		for i, value := range values {
+			out.Parameters[i], _ = strconv.Atoi(value)
+		}
+	}
+	return out
+}
+
+// removeUnsupportedExpressions maps every operand once into mapped, so the element registry counts each
+// condition only once, and removes the operands that are unknown.
+func removeUnsupportedExpressions(node *ConditionTreeNode, data GameData, elements *IDRegistry, mapped map[*ConditionTreeNode]MappedMultilangCondition) (*ConditionTreeNode, error) {
 	if node == nil {
-		return nil
+		return nil, nil
 	}
 
-	// If the node is an operand and not supported, return nil
-	validCond, _ := atomicCondition(node.Value, langs, data)
-	if node.Type == Operand && !validCond {
-		return nil
+	if node.Type == Operand {
+		validCond, mappedCond, err := atomicCondition(node.Value, data, elements)
+		if err != nil {
+			return nil, err
+		}
+		if !validCond {
+			if !data.dialect().unknownCriteria {
+				return nil, nil
+			}
+			mappedCond = rawCondition(node.Value)
+			if known, found := lookupCriterion(mappedCond.Element); found {
+				mappedCond.Kind, mappedCond.ValueKinds = known.kind, known.values // like "ST=1" that has nothing to show
+			}
+		}
+		mapped[node] = mappedCond
 	}
 
 	// Process children
 	var validChildren []*ConditionTreeNode
 	for _, child := range node.Children {
-		processedChild := removeUnsupportedExpressions(child, langs, data)
+		processedChild, err := removeUnsupportedExpressions(child, data, elements, mapped)
+		if err != nil {
+			return nil, err
+		}
 		if processedChild != nil {
 			validChildren = append(validChildren, processedChild)
 		}
 	}
 	node.Children = validChildren
 
-	return node
+	return node, nil
 }
 
 func simplifyTree(node *ConditionTreeNode) *ConditionTreeNode {
@@ -441,15 +583,15 @@ func simplifyTree(node *ConditionTreeNode) *ConditionTreeNode {
 	return node
 }
 
-func buildMappedConditionTree(out **ConditionTreeNodeMapped, root *ConditionTreeNode, langs *map[string]LangDict, data *JSONGameData) {
+func buildMappedConditionTree(out **ConditionTreeNodeMapped, root *ConditionTreeNode, mapped map[*ConditionTreeNode]MappedMultilangCondition) error {
 	if root == nil {
-		return
+		return nil
 	}
 
 	if root.Type == Operand {
-		foundCond, mappedCond := atomicCondition(root.Value, langs, data)
-		if !foundCond {
-			log.Fatal("condition not found, should be handled before")
+		mappedCond, found := mapped[root]
+		if !found {
+			return fmt.Errorf("condition %q not found, should be handled before", root.Value)
 		}
 
 		if *out == nil {
@@ -458,7 +600,7 @@ func buildMappedConditionTree(out **ConditionTreeNodeMapped, root *ConditionTree
 
 		(*out).Value = &mappedCond
 		(*out).IsOperand = true
-		return
+		return nil
 	} else if root.Type == Operator {
 		if *out == nil {
 			*out = new(ConditionTreeNodeMapped)
@@ -471,17 +613,20 @@ func buildMappedConditionTree(out **ConditionTreeNodeMapped, root *ConditionTree
 			(*out).Relation = new(string)
 			*(*out).Relation = "or"
 		} else {
-			log.Fatal("unknown operator")
+			return fmt.Errorf("unknown operator %q", root.Value)
 		}
 		(*out).Children = make([]*ConditionTreeNodeMapped, len(root.Children))
 		for i, child := range root.Children {
 			childOut := new(*ConditionTreeNodeMapped)
-			buildMappedConditionTree(childOut, child, langs, data)
+			err := buildMappedConditionTree(childOut, child, mapped)
+			if err != nil {
+				return err
+			}
 			(*out).Children[i] = *childOut
 		}
-		return
+		return nil
 	} else {
-		log.Fatal("unknown node type")
+		return fmt.Errorf("unknown node type %d", root.Type)
 	}
 }
 
@@ -502,7 +647,9 @@ func buildHistoricAndConnectionArray(mappedTree *ConditionTreeNodeMapped, out *[
 	}
 
 	if mappedTree.IsOperand {
-		*out = append(*out, *mappedTree.Value)
+		if !mappedTree.Value.Raw {
+			*out = append(*out, *mappedTree.Value)
+		}
 		return
 	}
 
@@ -513,28 +660,47 @@ func buildHistoricAndConnectionArray(mappedTree *ConditionTreeNodeMapped, out *[
 	}
 }
 
-func ParseCondition(condition string, langs *map[string]LangDict, data *JSONGameData) ([]MappedMultilangCondition, *ConditionTreeNodeMapped) {
-	if condition == "" || (!strings.Contains(condition, "&") && !strings.Contains(condition, "|") && !strings.Contains(condition, "<") && !strings.Contains(condition, ">")) {
-		return nil, nil
+func ParseCondition(condition string, langs *map[string]LangDict, data *JSONGameData, elements *IDRegistry) ([]MappedMultilangCondition, *ConditionTreeNodeMapped, error) {
+	return parseCondition(condition, NewGameData(data, *langs), elements)
+}
+
+// parseCondition returns the &-connected conditions as flat list and the complete condition tree.
+func parseCondition(condition string, data GameData, elements *IDRegistry) ([]MappedMultilangCondition, *ConditionTreeNodeMapped, error) {
+	if condition == "" {
+		return nil, nil, nil
+	}
+	// quest criteria are often a single "Qf=123", and raw conditions should keep everything
+	if !data.dialect().questCriteria && !data.dialect().unknownCriteria && !strings.Contains(condition, "&") && !strings.Contains(condition, "|") && !strings.Contains(condition, "<") && !strings.Contains(condition, ">") {
+		return nil, nil, nil
 	}
 
 	// parse into ast
-	tree := ParseExpression(condition)
+	tree, err := parseExpression(condition)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// strip tree to only known conditions
-	tree = removeUnsupportedExpressions(tree, langs, data)
+	mapped := make(map[*ConditionTreeNode]MappedMultilangCondition)
+	tree, err = removeUnsupportedExpressions(tree, data, elements, mapped)
+	if err != nil {
+		return nil, nil, err
+	}
 	tree = simplifyTree(tree)
 
 	if tree == nil {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// convert to mapped tree
 	mappedTree := new(*ConditionTreeNodeMapped)
-	buildMappedConditionTree(mappedTree, tree, langs, data)
+	err = buildMappedConditionTree(mappedTree, tree, mapped)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if *mappedTree == nil {
-		log.Fatal("mapped tree is nil")
+		return nil, nil, fmt.Errorf("mapped tree of %q is nil", condition)
 	}
 
 	// for historical reasons, still return the old format but only for &-connected conditions
@@ -545,7 +711,20 @@ func ParseCondition(condition string, langs *map[string]LangDict, data *JSONGame
 		mappedConditions = nil
 	}
 
-	return mappedConditions, *mappedTree
+	return mappedConditions, *mappedTree, nil
+}
+
+// ParseConditionKeepUnknown is ParseCondition but keeps criteria it can not map as raw conditions with
+// the original code, operator and value. The tree then has the same and/or structure as the criterion.
+// Raw conditions are not part of the flat list.
+func ParseConditionKeepUnknown(condition string, langs *map[string]LangDict, data *JSONGameData, elements *IDRegistry) ([]MappedMultilangCondition, *ConditionTreeNodeMapped, error) {
+	return parseCondition(condition, withUnknownCriteria(NewGameData(data, *langs)), elements)
+}
+
+// ParseQuestCondition is ParseCondition for quest start criteria, which keeps single conditions like
+// "Qf=123" that item criteria skip.
+func ParseQuestCondition(condition string, langs *map[string]LangDict, data *JSONGameData, elements *IDRegistry) ([]MappedMultilangCondition, *ConditionTreeNodeMapped, error) {
+	return parseCondition(condition, withQuestCriteria(NewGameData(data, *langs)), elements)
 }
 
 // TODO: previous "conditions" convert to only be with simple & operator
@@ -561,25 +740,50 @@ func CleanJSON(jsonStr string) string {
 	return jsonStr
 }
 
-func ParseRawDataPart[T HasId](fileSource string, result chan map[int]T, dir string) {
-	file, err := os.ReadFile(filepath.Join(dir, fileSource))
+// ParseRawDataPart reads a Dofus 2 json array from fileSource in fsys into a map keyed by id.
+// Entities with a field of an unexpected type are still added with that field zeroed.
+func ParseRawDataPart[T HasId](fileSource string, result chan map[int]T, errs chan error, fsys fs.FS) {
+	items := make(map[int]T)
+	file, err := fs.ReadFile(fsys, fileSource)
 	if err != nil {
-		fmt.Print(err)
+		result <- items
+		errs <- newDataError(fileSource, -1, "", err)
+		return
 	}
 	fileStr := CleanJSON(string(file))
-	var fileJson []T
+	var fileJson []json.RawMessage
 	err = json.Unmarshal([]byte(fileStr), &fileJson)
 	if err != nil {
-		fmt.Println(err)
+		result <- items
+		errs <- decodeError(fileSource, -1, err)
+		return
 	}
-	items := make(map[int]T)
-	for _, item := range fileJson {
+
+	var entryErrs []error
+	for _, entry := range fileJson {
+		var item T
+		err = json.Unmarshal(entry, &item)
+		if err != nil {
+			entryErrs = append(entryErrs, decodeError(fileSource, rawEntityId(entry), err))
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				continue
+			}
+		}
 		items[item.GetID()] = item
 	}
 	result <- items
+	errs <- errors.Join(entryErrs...)
+}
+
+// ParseRawData loads all Dofus 2 game data files from dir. See ParseRawDataFS.
+func ParseRawData(dir string) (*JSONGameData, error) {
+	return ParseRawDataFS(os.DirFS(dir))
 }
 
-func ParseRawData(dir string) *JSONGameData {
+// ParseRawDataFS loads all Dofus 2 game data files from the root of fsys. The returned data is never nil and contains
+// everything that could be parsed. The error joins a DataError for every file or entity that failed.
+func ParseRawDataFS(fsys fs.FS) (*JSONGameData, error) {
 	var data JSONGameData
 	itemChan := make(chan map[int]JSONGameItem)
 	itemTypeChan := make(chan map[int]JSONGameItemType)
@@ -589,6 +793,7 @@ func ParseRawData(dir string) *JSONGameData {
 	itemRecipesChang := make(chan map[int]JSONGameRecipe)
 	spellsChan := make(chan map[int]JSONGameSpell)
 	spellTypesChan := make(chan map[int]JSONGameSpellType)
+	spellLevelsChan := make(chan map[int]JSONGameSpellLevel)
 	areasChan := make(chan map[int]JSONGameArea)
 	mountsChan := make(chan map[int]JSONGameMount)
 	breedsChan := make(chan map[int]JSONGameBreed)
@@ -601,67 +806,108 @@ func ParseRawData(dir string) *JSONGameData {
 	questCategoriesChan := make(chan map[int]JSONGameQuestCategory)
 	questStepsChan := make(chan map[int]JSONGameQuestStep)
 	almanaxCalendarsChan := make(chan map[int]JSONGameAlamanaxCalendar)
+	monstersChan := make(chan map[int]JSONGameMonster)
+	monsterRacesChan := make(chan map[int]JSONGameMonsterRace)
+	jobsChan := make(chan map[int]JSONGameJob)
+	skillsChan := make(chan map[int]JSONGameSkill)
+	superAreasChan := make(chan map[int]JSONGameSuperArea)
+	subAreasChan := make(chan map[int]JSONGameSubArea)
+	emotesChan := make(chan map[int]JSONGameEmote)
+	achievementsChan := make(chan map[int]JSONGameAchievement)
+	errs := make(chan error) // every part sends its data first, so collectErrors only starts after them
+	parts := 0
+	start := func(part func()) {
+		parts++
+		go part()
+	}
 
-	go func() {
-		ParseRawDataPart("npcs.json", npcsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("mount_family.json", mountFamilyChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("breeds.json", breedsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("mounts.json", mountsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("areas.json", areasChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("spell_types.json", spellTypesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("spells.json", spellsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("recipes.json", itemRecipesChang, dir)
-	}()
-	go func() {
-		ParseRawDataPart("items.json", itemChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("item_types.json", itemTypeChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("item_sets.json", itemSetsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("bonuses.json", itemBonusesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("effects.json", itemEffectsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("titles.json", titlesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("quests.json", questsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("quest_objectives.json", questObjectivesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("quest_step_rewards.json", questStepRewardsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("quest_categories.json", questCategoriesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("almanax.json", almanaxCalendarsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPart("quest_steps.json", questStepsChan, dir)
-	}()
+	start(func() {
+		ParseRawDataPart("npcs.json", npcsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("mount_family.json", mountFamilyChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("breeds.json", breedsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("mounts.json", mountsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("areas.json", areasChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("spell_types.json", spellTypesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("spells.json", spellsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("spell_levels.json", spellLevelsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("recipes.json", itemRecipesChang, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("items.json", itemChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("item_types.json", itemTypeChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("item_sets.json", itemSetsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("bonuses.json", itemBonusesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("effects.json", itemEffectsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("titles.json", titlesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("quests.json", questsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("quest_objectives.json", questObjectivesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("quest_step_rewards.json", questStepRewardsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("quest_categories.json", questCategoriesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("almanax.json", almanaxCalendarsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("quest_steps.json", questStepsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("monsters.json", monstersChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("monster_races.json", monsterRacesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("jobs.json", jobsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("skills.json", skillsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("super_areas.json", superAreasChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("sub_areas.json", subAreasChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("emotes.json", emotesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPart("achievements.json", achievementsChan, errs, fsys)
+	})
 
 	data.Items = <-itemChan
 	close(itemChan)
@@ -687,6 +933,9 @@ func ParseRawData(dir string) *JSONGameData {
 	data.spellTypes = <-spellTypesChan
 	close(spellTypesChan)
 
+	data.spellLevels = <-spellLevelsChan
+	close(spellLevelsChan)
+
 	data.areas = <-areasChan
 	close(areasChan)
 
@@ -723,34 +972,66 @@ func ParseRawData(dir string) *JSONGameData {
 	data.questSteps = <-questStepsChan
 	close(questStepsChan)
 
-	return &data
+	data.monsters = <-monstersChan
+	close(monstersChan)
+
+	data.monsterRaces = <-monsterRacesChan
+	close(monsterRacesChan)
+
+	data.jobs = <-jobsChan
+	close(jobsChan)
+
+	data.skills = <-skillsChan
+	close(skillsChan)
+
+	data.superAreas = <-superAreasChan
+	close(superAreasChan)
+
+	data.subAreas = <-subAreasChan
+	close(subAreasChan)
+
+	data.emotes = <-emotesChan
+	close(emotesChan)
+
+	data.achievements = <-achievementsChan
+	close(achievementsChan)
+
+	return &data, collectErrors(errs, parts)
+}
+
+// ParseLangDict reads the Dofus 2 translations for langCode from dir/languages.
+func ParseLangDict(langCode string, dir string) (LangDict, error) {
+	return ParseLangDictFS(langCode, os.DirFS(dir))
 }
 
-func ParseLangDict(langCode string, dir string) LangDict {
+// ParseLangDictFS reads the Dofus 2 translations for langCode from languages/ in fsys.
+func ParseLangDictFS(langCode string, fsys fs.FS) (LangDict, error) {
 	var err error
 
-	dataPath := filepath.Join(dir, "languages")
+	fileSource := path.Join("languages", langCode+".json")
 	var data LangDict
 	data.IdText = make(map[int]int)
 	data.Texts = make(map[int]string)
 	data.NameText = make(map[string]int)
 
-	langFile, err := os.ReadFile(filepath.Join(dataPath, langCode+".json"))
+	langFile, err := fs.ReadFile(fsys, fileSource)
 	if err != nil {
-		fmt.Print(err)
+		return data, newDataError(fileSource, -1, "", err)
 	}
 
 	langFileStr := CleanJSON(string(langFile))
 	var langJson JSONLangDict
 	err = json.Unmarshal([]byte(langFileStr), &langJson)
 	if err != nil {
-		fmt.Println(err)
+		return data, decodeError(fileSource, -1, err)
 	}
 
+	var errs []error
 	for key, value := range langJson.IdText {
 		keyParsed, err := strconv.Atoi(key)
 		if err != nil {
-			fmt.Println(err)
+			errs = append(errs, newDataError(fileSource, -1, "idText."+key, err))
+			continue
 		}
 		data.IdText[keyParsed] = value
 	}
@@ -758,18 +1039,33 @@ func ParseLangDict(langCode string, dir string) LangDict {
 	for key, value := range langJson.Texts {
 		keyParsed, err := strconv.Atoi(key)
 		if err != nil {
-			fmt.Println(err)
+			errs = append(errs, newDataError(fileSource, -1, "texts."+key, err))
+			continue
 		}
 		data.Texts[keyParsed] = value
 	}
-	data.NameText = langJson.NameText
-	return data
+	if langJson.NameText != nil {
+		data.NameText = langJson.NameText
+	}
+	return data, errors.Join(errs...)
+}
+
+// ParseRawLanguages loads the translations of all Languages from dir. See ParseRawLanguagesFS.
+func ParseRawLanguages(dir string) (map[string]LangDict, error) {
+	return ParseRawLanguagesFS(os.DirFS(dir))
 }
 
-func ParseRawLanguages(dir string) map[string]LangDict {
+// ParseRawLanguagesFS loads the translations of all Languages from fsys. Languages that failed to load are
+// still part of the result with whatever could be read.
+func ParseRawLanguagesFS(fsys fs.FS) (map[string]LangDict, error) {
 	data := make(map[string]LangDict)
+	var errs []error
 	for _, lang := range Languages {
-		data[lang] = ParseLangDict(lang, dir)
+		dict, err := ParseLangDictFS(lang, fsys)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		data[lang] = dict
 	}
-	return data
+	return data, errors.Join(errs...)
 }
@@ -1,11 +1,17 @@
 package dodumap
 
 type MappedMultilangCondition struct {
-	Element   string            `json:"element"`
-	ElementId int               `json:"element_id"`
-	Operator  string            `json:"operator"`
-	Value     int               `json:"value"`
-	Templated map[string]string `json:"templated"`
+	Element    string               `json:"element"`
+	ElementId  int                  `json:"element_id"`
+	Operator   string               `json:"operator"`
+	Value      int                  `json:"value"`
+	Parameters []int                `json:"parameters,omitempty"` // all values of criteria like "PJ>2,40", Value is the last one
+	Templated  map[string]string    `json:"templated"`
+	Subject    map[string]string    `json:"subject,omitempty"` // name of the quest, job, item, ... the condition refers to
+	Kind       ConditionKind        `json:"kind"`
+	ValueKinds []ConditionValueKind `json:"value_kinds,omitempty"` // meaning of Value, or of every entry in Parameters
+	Raw        bool                 `json:"raw,omitempty"`         // unknown criterion that was kept as it is, Templated is empty
+	Criterion  string               `json:"criterion,omitempty"`   // original text of raw criteria like "PX=0"
 }
 
 // NodeType defines the type of a node - either an operator or an operand
@@ -32,6 +38,9 @@ type ConditionTreeNodeMapped struct {
 type MappedMultilangRecipe struct {
 	ResultId int                          `json:"result_id"`
 	Entries  []MappedMultilangRecipeEntry `json:"entries"`
+	JobId    int                          `json:"job_id"`
+	SkillId  int                          `json:"skill_id"`
+	Level    int                          `json:"level"` // job level needed to craft
 }
 
 type MappedMultilangRecipeEntry struct {
@@ -62,6 +71,122 @@ type MappedMultilangMount struct {
 	Effects    []MappedMultilangEffect `json:"effects"`
 }
 
+type MappedMultilangSpellType struct {
+	Id        int               `json:"id"`
+	LongName  map[string]string `json:"long_name"`
+	ShortName map[string]string `json:"short_name"`
+}
+
+type MappedMultilangSpellLevel struct {
+	AnkamaId               int                     `json:"ankama_id"`
+	Grade                  int                     `json:"grade"`
+	MinPlayerLevel         int                     `json:"minPlayerLevel"`
+	ApCost                 int                     `json:"apCost"`
+	MinRange               int                     `json:"minRange"`
+	Range                  int                     `json:"range"`
+	RangeCanBeBoosted      bool                    `json:"rangeCanBeBoosted"`
+	CastInLine             bool                    `json:"castInLine"`
+	CastInDiagonal         bool                    `json:"castInDiagonal"`
+	CastTestLos            bool                    `json:"castTestLos"`
+	CriticalHitProbability int                     `json:"criticalHitProbability"`
+	MaxCastPerTurn         int                     `json:"maxCastPerTurn"`
+	MaxCastPerTarget       int                     `json:"maxCastPerTarget"`
+	Cooldown               int                     `json:"cooldown"` // turns between two casts
+	InitialCooldown        int                     `json:"initialCooldown"`
+	GlobalCooldown         int                     `json:"globalCooldown"`
+	Effects                []MappedMultilangEffect `json:"effects"`
+	CriticalEffects        []MappedMultilangEffect `json:"criticalEffects"`
+}
+
+type MappedMultilangSpell struct {
+	AnkamaId    int                         `json:"ankama_id"`
+	Name        map[string]string           `json:"name"`
+	Description map[string]string           `json:"description"`
+	Type        MappedMultilangSpellType    `json:"type"`
+	IconId      int                         `json:"iconId"`
+	Order       int                         `json:"order"`
+	Levels      []MappedMultilangSpellLevel `json:"levels"` // ordered by grade
+}
+
+type MappedMultilangItemLink struct {
+	Id   int               `json:"id"`
+	Name map[string]string `json:"name"`
+}
+
+type MappedMultilangBreedRole struct {
+	RoleId      int               `json:"role_id"`
+	Value       int               `json:"value"` // how well the breed fits the role
+	Description map[string]string `json:"description"`
+}
+
+type MappedMultilangBreed struct {
+	AnkamaId            int                        `json:"ankama_id"`
+	ShortName           map[string]string          `json:"short_name"`
+	LongName            map[string]string          `json:"long_name"`
+	Description         map[string]string          `json:"description"`
+	GameplayDescription map[string]string          `json:"gameplay_description"`
+	Roles               []MappedMultilangBreedRole `json:"roles"` // in display order
+	SpellIds            []int                      `json:"spell_ids"`
+	GuideItem           *MappedMultilangItemLink   `json:"guide_item"`
+	Complexity          int                        `json:"complexity"`
+	SortIndex           int                        `json:"sort_index"`
+}
+
+type MappedMultilangQuestCategory struct {
+	Id    int               `json:"id"`
+	Name  map[string]string `json:"name"`
+	Order int               `json:"order"`
+}
+
+type MappedMultilangQuestObjective struct {
+	AnkamaId    int                `json:"ankama_id"`
+	TypeId      int                `json:"type_id"`
+	MapId       int                `json:"map_id"`
+	Coords      JSONGameCoordinate `json:"coords"`
+	DungeonOnly bool               `json:"dungeon_only"`
+	Parameters  []int              `json:"parameters"`
+}
+
+type MappedQuestItemReward struct {
+	ItemId   int `json:"item_id"`
+	Quantity int `json:"quantity"`
+}
+
+type MappedMultilangQuestReward struct {
+	AnkamaId                  int                     `json:"ankama_id"`
+	Kamas                     int                     `json:"kamas"`      // at the optimal level of the step
+	Experience                int                     `json:"experience"` // at the optimal level of the step
+	KamasScaleWithPlayerLevel bool                    `json:"kamas_scale_with_player_level"`
+	LevelMin                  int                     `json:"level_min"`
+	LevelMax                  int                     `json:"level_max"`
+	Items                     []MappedQuestItemReward `json:"items"`
+}
+
+type MappedMultilangQuestStep struct {
+	AnkamaId     int                             `json:"ankama_id"`
+	Name         map[string]string               `json:"name"`
+	Description  map[string]string               `json:"description"`
+	OptimalLevel int                             `json:"optimal_level"`
+	Duration     float64                         `json:"duration"`
+	Objectives   []MappedMultilangQuestObjective `json:"objectives"`
+	Rewards      []MappedMultilangQuestReward    `json:"rewards"`
+}
+
+type MappedMultilangQuest struct {
+	AnkamaId       int                          `json:"ankama_id"`
+	Name           map[string]string            `json:"name"`
+	Category       MappedMultilangQuestCategory `json:"category"`
+	LevelMin       int                          `json:"level_min"`
+	LevelMax       int                          `json:"level_max"`
+	RepeatType     int                          `json:"repeat_type"`
+	RepeatLimit    int                          `json:"repeat_limit"`
+	IsDungeonQuest bool                         `json:"is_dungeon_quest"`
+	IsPartyQuest   bool                         `json:"is_party_quest"`
+	Followable     bool                         `json:"followable"`
+	StartCondition *ConditionTreeNodeMapped     `json:"start_condition"` // why the quest can not be started yet
+	Steps          []MappedMultilangQuestStep   `json:"steps"`           // in the order they have to be done
+}
+
 type MappedMultilangCharacteristic struct {
 	Value map[string]string `json:"value"`
 	Name  map[string]string `json:"name"`
@@ -80,14 +205,132 @@ type MappedMultilangSetEffect struct {
 }
 
 type MappedMultilangEffect struct {
-	Min              int               `json:"min"`
-	Max              int               `json:"max"`
-	Type             map[string]string `json:"type"`
-	MinMaxIrrelevant int               `json:"min_max_irrelevant"`
-	Templated        map[string]string `json:"templated"`
-	ElementId        int               `json:"element_id"`
-	IsMeta           bool              `json:"is_meta"`
-	Active           bool              `json:"active"`
+	Min              int                   `json:"min"`
+	Max              int                   `json:"max"`
+	Type             map[string]string     `json:"type"`
+	MinMaxIrrelevant int                   `json:"min_max_irrelevant"`
+	Templated        map[string]string     `json:"templated"`
+	ElementId        int                   `json:"element_id"`
+	IsMeta           bool                  `json:"is_meta"`
+	Active           bool                  `json:"active"`
+	Title            *MappedMultilangTitle `json:"title,omitempty"`            // the title a title effect gives
+	TemplatedFemale  map[string]string     `json:"templated_female,omitempty"` // Templated with the female title, only for title effects
+}
+
+type MappedMultilangSkill struct {
+	AnkamaId              int               `json:"ankama_id"`
+	Name                  map[string]string `json:"name"`
+	LevelMin              int               `json:"level_min"`
+	IsForgemagus          bool              `json:"is_forgemagus"`
+	GatheredItemId        int               `json:"gathered_item_id"` // -1 when the skill does not gather
+	CraftableItemIds      []int             `json:"craftable_item_ids"`
+	ModifiableItemTypeIds []int             `json:"modifiable_item_type_ids"`
+}
+
+type MappedMultilangJob struct {
+	AnkamaId int                    `json:"ankama_id"`
+	Name     map[string]string      `json:"name"`
+	IconId   int                    `json:"icon_id"`
+	Skills   []MappedMultilangSkill `json:"skills"`
+}
+
+type MappedMultilangMonsterRace struct {
+	Id          int               `json:"id"`
+	Name        map[string]string `json:"name"`
+	SuperRaceId int               `json:"super_race_id"`
+}
+
+type MappedMonsterGrade struct {
+	Grade             int `json:"grade"`
+	Level             int `json:"level"`
+	LifePoints        int `json:"life_points"`
+	ActionPoints      int `json:"action_points"`
+	MovementPoints    int `json:"movement_points"`
+	Vitality          int `json:"vitality"`
+	Wisdom            int `json:"wisdom"`
+	Strength          int `json:"strength"`
+	Intelligence      int `json:"intelligence"`
+	Chance            int `json:"chance"`
+	Agility           int `json:"agility"`
+	ApDodge           int `json:"ap_dodge"`
+	MpDodge           int `json:"mp_dodge"`
+	EarthResistance   int `json:"earth_resistance"`
+	AirResistance     int `json:"air_resistance"`
+	FireResistance    int `json:"fire_resistance"`
+	WaterResistance   int `json:"water_resistance"`
+	NeutralResistance int `json:"neutral_resistance"`
+	Experience        int `json:"experience"`
+}
+
+type MappedMonsterDrop struct {
+	ItemId        int       `json:"item_id"`
+	Count         int       `json:"count"`
+	Rates         []float64 `json:"rates"` // drop chance in percent, one per grade
+	HasConditions bool      `json:"has_conditions"`
+}
+
+type MappedMultilangMonster struct {
+	AnkamaId          int                        `json:"ankama_id"`
+	Name              map[string]string          `json:"name"`
+	Race              MappedMultilangMonsterRace `json:"race"`
+	IsBoss            bool                       `json:"is_boss"`
+	IsMiniBoss        bool                       `json:"is_mini_boss"`
+	IsQuestMonster    bool                       `json:"is_quest_monster"`
+	SubareaIds        []int                      `json:"subarea_ids"`
+	FavoriteSubareaId int                        `json:"favorite_subarea_id"`
+	Grades            []MappedMonsterGrade       `json:"grades"`
+	Drops             []MappedMonsterDrop        `json:"drops"`
+}
+
+// MappedMultilangNPCDialog is a message of an npc or a reply the player can give. Messages link the
+// replies that answer them with ReplyIds, from the values after the text id of their dialogMessages entry.
+type MappedMultilangNPCDialog struct {
+	Id       int               `json:"id"`
+	Text     map[string]string `json:"text"`
+	ReplyIds []int             `json:"reply_ids,omitempty"` // only messages
+}
+
+type MappedMultilangNPC struct {
+	AnkamaId int                        `json:"ankama_id"`
+	Name     map[string]string          `json:"name"`
+	Messages []MappedMultilangNPCDialog `json:"messages"`
+	Replies  []MappedMultilangNPCDialog `json:"replies"`
+	Actions  []int                      `json:"actions"`
+}
+
+type MappedMultilangArea struct {
+	AnkamaId        int                      `json:"ankama_id"`
+	Name            map[string]string        `json:"name"`
+	SuperAreaId     int                      `json:"super_area_id"`
+	ContainHouses   bool                     `json:"contain_houses"`
+	ContainPaddocks bool                     `json:"contain_paddocks"`
+	Bounds          JSONGameAreaBounds       `json:"bounds"` // in world map coordinates
+	WorldmapId      int                      `json:"worldmap_id"`
+	HasWorldMap     bool                     `json:"has_world_map"`
+	SubAreas        []MappedMultilangSubArea `json:"sub_areas"`
+}
+
+// MappedMultilangSubArea is the part of an area that monsters, NPCs and "PB" conditions refer to.
+type MappedMultilangSubArea struct {
+	AnkamaId int               `json:"ankama_id"`
+	Name     map[string]string `json:"name"`
+	Level    int               `json:"level"`
+}
+
+// MappedMultilangSuperArea groups the areas of a continent or dimension. Name is nil when the super area
+// is not in the data, since older dumps have no super_areas.json.
+type MappedMultilangSuperArea struct {
+	AnkamaId int                   `json:"ankama_id"`
+	Name     map[string]string     `json:"name"`
+	Areas    []MappedMultilangArea `json:"areas"`
+}
+
+type MappedMultilangTitle struct {
+	AnkamaId   int               `json:"ankama_id"`
+	NameMale   map[string]string `json:"name_male"`
+	NameFemale map[string]string `json:"name_female"` // the male name when there is no female one
+	Visible    bool              `json:"visible"`
+	CategoryId int               `json:"category_id"`
 }
 
 type MappedMultilangItemType struct {
@@ -126,6 +369,7 @@ type MappedMultilangItem struct {
 
 type MappedMultilangNPCAlmanax struct {
 	OfferingReceiver string   `json:"offeringReceiver"`
+	NpcId            int      `json:"npcId"`
 	Days             []string `json:"days"`
 	Offering         struct {
 		ItemId    int               `json:"itemId"`
@@ -167,6 +411,32 @@ func (i JSONGameSpell) GetID() int {
 	return i.Id
 }
 
+type JSONGameSpellLevel struct {
+	Id                     int                           `json:"id"`
+	SpellId                int                           `json:"spellId"`
+	Grade                  int                           `json:"grade"`
+	ApCost                 int                           `json:"apCost"`
+	MinRange               int                           `json:"minRange"`
+	Range                  int                           `json:"range"`
+	CastInLine             bool                          `json:"castInLine"`
+	CastInDiagonal         bool                          `json:"castInDiagonal"`
+	CastTestLos            bool                          `json:"castTestLos"`
+	CriticalHitProbability int                           `json:"criticalHitProbability"`
+	RangeCanBeBoosted      bool                          `json:"rangeCanBeBoosted"`
+	MaxCastPerTurn         int                           `json:"maxCastPerTurn"`
+	MaxCastPerTarget       int                           `json:"maxCastPerTarget"`
+	MinCastInterval        int                           `json:"minCastInterval"`
+	InitialCooldown        int                           `json:"initialCooldown"`
+	GlobalCooldown         int                           `json:"globalCooldown"`
+	MinPlayerLevel         int                           `json:"minPlayerLevel"`
+	Effects                []*JSONGameItemPossibleEffect `json:"effects"`
+	CriticalEffect         []*JSONGameItemPossibleEffect `json:"criticalEffect"`
+}
+
+func (i JSONGameSpellLevel) GetID() int {
+	return i.Id
+}
+
 type JSONLangDict struct {
 	Texts    map[string]string `json:"texts"`    // "1": "Account- oder Abohandel",
 	IdText   map[string]int    `json:"idText"`   // "790745": 27679,
@@ -227,6 +497,28 @@ func (i JSONGameArea) GetID() int {
 	return i.Id
 }
 
+type JSONGameSuperArea struct {
+	Id          int  `json:"id"`
+	NameId      int  `json:"nameId"`
+	WorldmapId  int  `json:"worldmapId"`
+	HasWorldMap bool `json:"hasWorldMap"`
+}
+
+func (i JSONGameSuperArea) GetID() int {
+	return i.Id
+}
+
+type JSONGameSubArea struct {
+	Id     int `json:"id"`
+	NameId int `json:"nameId"`
+	AreaId int `json:"areaId"`
+	Level  int `json:"level"`
+}
+
+func (i JSONGameSubArea) GetID() int {
+	return i.Id
+}
+
 type JSONGameItemPossibleEffect struct {
 	EffectId     int `json:"effectId"`
 	MinimumValue int `json:"diceNum"`
@@ -314,10 +606,25 @@ func (i JSONGameItem) GetID() int {
 }
 
 type JSONGameBreed struct {
-	Id            int `json:"id"`
-	ShortNameId   int `json:"shortNameId"`
-	LongNameId    int `json:"longNameId"`
+	Id                         int                 `json:"id"`
+	ShortNameId                int                 `json:"shortNameId"`
+	LongNameId                 int                 `json:"longNameId"`
+	DescriptionId              int                 `json:"descriptionId"`
+	GameplayClassDescriptionId int                 `json:"gameplayClassDescriptionId"`
+	GuideItemId                int                 `json:"guideItemId"`
+	BreedSpellsId              []int               `json:"breedSpellsId"`
+	BreedRoles                 []JSONGameBreedRole `json:"breedRoles"`
+	Complexity                 int                 `json:"complexity"`
+	SortIndex                  int                 `json:"sortIndex"`
+}
+
+// JSONGameBreedRole rates how well a breed fits a role like damage or support.
+type JSONGameBreedRole struct {
+	BreedId       int `json:"breedId"`
+	RoleId        int `json:"roleId"`
 	DescriptionId int `json:"descriptionId"`
+	Value         int `json:"value"`
+	Order         int `json:"order"`
 }
 
 func (i JSONGameBreed) GetID() int {
@@ -348,8 +655,8 @@ func (i JSONGameMountFamily) GetID() int {
 type JSONGameNPC struct {
 	Id             int     `json:"id"`
 	NameId         int     `json:"nameId"`
-	DialogMessages [][]int `json:"dialogMessages"`
-	DialogReplies  [][]int `json:"dialogReplies"`
+	DialogMessages [][]int `json:"dialogMessages"` // message id, text id, reply ids
+	DialogReplies  [][]int `json:"dialogReplies"`  // reply id, text id
 	Actions        []int   `json:"actions"`
 }
 
@@ -446,11 +753,12 @@ func (i JSONGameQuestStepRewards) GetID() int {
 }
 
 type JSONGameAlamanaxCalendar struct {
-	Id         int   `json:"id"`
-	DescId     int   `json:"descId"`
-	NameId     int   `json:"nameId"`
-	NpcId      int   `json:"npcId"`
-	BonusesIds []int `json:"bonusesIds"`
+	Id         int      `json:"id"`
+	DescId     int      `json:"descId"`
+	NameId     int      `json:"nameId"`
+	NpcId      int      `json:"npcId"`
+	BonusesIds []int    `json:"bonusesIds"`
+	Dates      []string `json:"dates"` // only set for Dofus 3 data
 }
 
 func (i JSONGameAlamanaxCalendar) GetID() int {
@@ -473,6 +781,115 @@ func (i JSONGameQuestStep) GetID() int {
 	return i.Id
 }
 
+type JSONGameJob struct {
+	Id     int `json:"id"`
+	NameId int `json:"nameId"`
+	IconId int `json:"iconId"`
+}
+
+func (i JSONGameJob) GetID() int {
+	return i.Id
+}
+
+type JSONGameEmote struct {
+	Id     int `json:"id"`
+	NameId int `json:"nameId"`
+}
+
+func (i JSONGameEmote) GetID() int {
+	return i.Id
+}
+
+type JSONGameAchievement struct {
+	Id     int `json:"id"`
+	NameId int `json:"nameId"`
+}
+
+func (i JSONGameAchievement) GetID() int {
+	return i.Id
+}
+
+type JSONGameSkill struct {
+	Id                    int   `json:"id"`
+	NameId                int   `json:"nameId"`
+	ParentJobId           int   `json:"parentJobId"`
+	IsForgemagus          bool  `json:"isForgemagus"`
+	ModifiableItemTypeIds []int `json:"modifiableItemTypeIds"`
+	GatheredRessourceItem int   `json:"gatheredRessourceItem"` // -1 when the skill does not gather
+	CraftableItemIds      []int `json:"craftableItemIds"`
+	LevelMin              int   `json:"levelMin"`
+}
+
+func (i JSONGameSkill) GetID() int {
+	return i.Id
+}
+
+type JSONGameMonsterGrade struct {
+	Grade             int `json:"grade"`
+	MonsterId         int `json:"monsterId"`
+	Level             int `json:"level"`
+	LifePoints        int `json:"lifePoints"`
+	ActionPoints      int `json:"actionPoints"`
+	MovementPoints    int `json:"movementPoints"`
+	Vitality          int `json:"vitality"`
+	Wisdom            int `json:"wisdom"`
+	Strength          int `json:"strength"`
+	Intelligence      int `json:"intelligence"`
+	Chance            int `json:"chance"`
+	Agility           int `json:"agility"`
+	PaDodge           int `json:"paDodge"`
+	PmDodge           int `json:"pmDodge"`
+	EarthResistance   int `json:"earthResistance"`
+	AirResistance     int `json:"airResistance"`
+	FireResistance    int `json:"fireResistance"`
+	WaterResistance   int `json:"waterResistance"`
+	NeutralResistance int `json:"neutralResistance"`
+	GradeXp           int `json:"gradeXp"`
+}
+
+type JSONGameMonsterDrop struct {
+	DropId               int     `json:"dropId"`
+	MonsterId            int     `json:"monsterId"`
+	ObjectId             int     `json:"objectId"`
+	PercentDropForGrade1 float64 `json:"percentDropForGrade1"`
+	PercentDropForGrade2 float64 `json:"percentDropForGrade2"`
+	PercentDropForGrade3 float64 `json:"percentDropForGrade3"`
+	PercentDropForGrade4 float64 `json:"percentDropForGrade4"`
+	PercentDropForGrade5 float64 `json:"percentDropForGrade5"`
+	Count                int     `json:"count"`
+	Criteria             string  `json:"criteria"`
+	HasCriteria          bool    `json:"hasCriteria"`
+}
+
+type JSONGameMonster struct {
+	Id                int                    `json:"id"`
+	NameId            int                    `json:"nameId"`
+	GfxId             int                    `json:"gfxId"`
+	Race              int                    `json:"race"`
+	Grades            []JSONGameMonsterGrade `json:"grades"`
+	Drops             []JSONGameMonsterDrop  `json:"drops"`
+	Subareas          []int                  `json:"subareas"`
+	FavoriteSubareaId int                    `json:"favoriteSubareaId"`
+	IsBoss            bool                   `json:"isBoss"`
+	IsMiniBoss        bool                   `json:"isMiniBoss"`
+	IsQuestMonster    bool                   `json:"isQuestMonster"`
+}
+
+func (i JSONGameMonster) GetID() int {
+	return i.Id
+}
+
+type JSONGameMonsterRace struct {
+	Id          int   `json:"id"`
+	SuperRaceId int   `json:"superRaceId"`
+	NameId      int   `json:"nameId"`
+	Monsters    []int `json:"monsters"`
+}
+
+func (i JSONGameMonsterRace) GetID() int {
+	return i.Id
+}
+
 type JSONGameData struct {
 	Items            map[int]JSONGameItem
 	Sets             map[int]JSONGameSet
@@ -482,7 +899,12 @@ type JSONGameData struct {
 	Recipes          map[int]JSONGameRecipe
 	spells           map[int]JSONGameSpell
 	spellTypes       map[int]JSONGameSpellType
+	spellLevels      map[int]JSONGameSpellLevel
 	areas            map[int]JSONGameArea
+	superAreas       map[int]JSONGameSuperArea
+	subAreas         map[int]JSONGameSubArea
+	emotes           map[int]JSONGameEmote
+	achievements     map[int]JSONGameAchievement
 	Mounts           map[int]JSONGameMount
 	classes          map[int]JSONGameBreed
 	MountFamilys     map[int]JSONGameMountFamily
@@ -494,4 +916,8 @@ type JSONGameData struct {
 	quests           map[int]JSONGameQuest
 	questStepRewards map[int]JSONGameQuestStepRewards
 	almanaxCalendars map[int]JSONGameAlamanaxCalendar
+	monsters         map[int]JSONGameMonster
+	monsterRaces     map[int]JSONGameMonsterRace
+	jobs             map[int]JSONGameJob
+	skills           map[int]JSONGameSkill
 }
@@ -1,19 +1,20 @@
 package dodumap
 
-// changes: removed twoHanded and conditions
 type MappedMultilangItemUnity struct {
 	AnkamaId               int                             `json:"ankama_id"`
 	Type                   MappedMultilangItemType         `json:"type"`
 	Description            map[string]string               `json:"description"`
 	Name                   map[string]string               `json:"name"`
 	Image                  string                          `json:"image"`
-	Conditions             *ConditionTreeNodeMapped        `json:"conditions"`
+	Conditions             []MappedMultilangCondition      `json:"conditions"`
+	ConditionTree          *ConditionTreeNodeMapped        `json:"condition_tree"`
 	Level                  int                             `json:"level"`
 	UsedInRecipes          []int                           `json:"used_in_recipes"`
 	Characteristics        []MappedMultilangCharacteristic `json:"characteristics"`
 	Effects                []MappedMultilangEffect         `json:"effects"`
 	DropMonsterIds         []int                           `json:"dropMonsterIds"`
 	CriticalHitBonus       int                             `json:"criticalHitBonus"`
+	TwoHanded              bool                            `json:"twoHanded"`
 	MaxCastPerTurn         int                             `json:"maxCastPerTurn"`
 	ApCost                 int                             `json:"apCost"`
 	Range                  int                             `json:"range"`
@@ -27,6 +28,7 @@ type MappedMultilangItemUnity struct {
 
 type MappedMultilangNPCAlmanaxUnity struct {
 	OfferingReceiver string   `json:"offeringReceiver"`
+	NpcId            int      `json:"npcId"`
 	Days             []string `json:"days"`
 	DatesRule        []string `json:"datesRule"` // NOTE: Since 3.2
 	Offering         struct {
@@ -124,6 +126,7 @@ type JSONGameItemUnityRaw struct {
 	ItemSetId              int                        `json:"itemSetId"`
 	Criterions             string                     `json:"criterions"`
 	CriticalHitBonus       int                        `json:"criticalHitBonus"`
+	TwoHanded              int                        `json:"twoHanded"` // 0, 1
 	MaxCastPerTurn         int                        `json:"maxCastPerTurn"`
 	ApCost                 int                        `json:"apCost"`
 	Range                  int                        `json:"range"`
@@ -151,6 +154,7 @@ type JSONGameItemUnity struct {
 	ItemSetId              int                                `json:"itemSetId"`
 	Criterions             string                             `json:"criterions"`
 	CriticalHitBonus       int                                `json:"criticalHitBonus"`
+	TwoHanded              int                                `json:"twoHanded"` // 0, 1
 	MaxCastPerTurn         int                                `json:"maxCastPerTurn"`
 	ApCost                 int                                `json:"apCost"`
 	Range                  int                                `json:"range"`
@@ -174,6 +178,7 @@ func (i *JSONGameItemUnityRaw) Merge(other []*JSONGameItemPossibleEffectUnity) J
 		ItemSetId:              i.ItemSetId,
 		Criterions:             i.Criterions,
 		CriticalHitBonus:       i.CriticalHitBonus,
+		TwoHanded:              i.TwoHanded,
 		CriticalHitProbability: i.CriticalHitProbability,
 		ApCost:                 i.ApCost,
 		MaxCastPerTurn:         i.MaxCastPerTurn,
@@ -276,24 +281,18 @@ func (i JSONGameMountUnity) GetID() int {
 
 // actually a playable class
 type JSONGameBreedUnity struct {
-	Id                         int                        `json:"id"`
-	ShortNameId                string                     `json:"shortNameId"` // int
-	LongNameId                 string                     `json:"longNameId"`  // int
-	DescriptionId              int                        `json:"descriptionId"`
-	GameplayClassDescriptionId string                     `json:"gameplayClassDescriptionId"` // int
-	GuideItemId                int                        `json:"guideItemId"`
-	MaleArtwork                int                        `json:"maleArtwork"`
-	FemaleArtwork              int                        `json:"femaleArtwork"`
-	BreedSpellsId              JSONGameUnityAnkamaIdArray `json:"breedSpellsId"`
-	BreedRolesId               JSONGameUnityArray[struct {
-		BreedId       int `json:"breedId"`
-		RoleId        int `json:"roleId"`
-		DescriptionId int `json:"descriptionId"`
-		Value         int `json:"value"`
-		Order         int `json:"order"`
-	}] `json:"breedRolesId"`
-	Complexity int `json:"complexity"`
-	SortIndex  int `json:"sortIndex"`
+	Id                         int                                   `json:"id"`
+	ShortNameId                string                                `json:"shortNameId"` // int
+	LongNameId                 string                                `json:"longNameId"`  // int
+	DescriptionId              int                                   `json:"descriptionId"`
+	GameplayClassDescriptionId string                                `json:"gameplayClassDescriptionId"` // int
+	GuideItemId                int                                   `json:"guideItemId"`
+	MaleArtwork                int                                   `json:"maleArtwork"`
+	FemaleArtwork              int                                   `json:"femaleArtwork"`
+	BreedSpellsId              JSONGameUnityAnkamaIdArray            `json:"breedSpellsId"`
+	BreedRolesId               JSONGameUnityArray[JSONGameBreedRole] `json:"breedRolesId"`
+	Complexity                 int                                   `json:"complexity"`
+	SortIndex                  int                                   `json:"sortIndex"`
 }
 
 func (i JSONGameBreedUnity) GetID() int {
@@ -479,6 +478,78 @@ func (i JSONGameSpellUnity) GetID() int {
 	return i.Id
 }
 
+type JSONGameSpellLevelUnityRaw struct {
+	Id                     int                   `json:"id"`
+	SpellId                int                   `json:"spellId"`
+	Grade                  int                   `json:"grade"`
+	ApCost                 int                   `json:"apCost"`
+	MinRange               int                   `json:"minRange"`
+	Range                  int                   `json:"range"`
+	CastInLine             int                   `json:"castInLine"`     // bool
+	CastInDiagonal         int                   `json:"castInDiagonal"` // bool
+	CastTestLos            int                   `json:"castTestLos"`    // bool
+	CriticalHitProbability int                   `json:"criticalHitProbability"`
+	RangeCanBeBoosted      int                   `json:"rangeCanBeBoosted"` // bool
+	MaxCastPerTurn         int                   `json:"maxCastPerTurn"`
+	MaxCastPerTarget       int                   `json:"maxCastPerTarget"`
+	MinCastInterval        int                   `json:"minCastInterval"`
+	InitialCooldown        int                   `json:"initialCooldown"`
+	GlobalCooldown         int                   `json:"globalCooldown"`
+	MinPlayerLevel         int                   `json:"minPlayerLevel"`
+	Effects                JSONGameUnityRefArray `json:"effects"`
+	CriticalEffect         JSONGameUnityRefArray `json:"criticalEffect"`
+}
+
+func (i JSONGameSpellLevelUnityRaw) GetID() int {
+	return i.Id
+}
+
+type JSONGameSpellLevelUnity struct {
+	Id                     int                                `json:"id"`
+	SpellId                int                                `json:"spellId"`
+	Grade                  int                                `json:"grade"`
+	ApCost                 int                                `json:"apCost"`
+	MinRange               int                                `json:"minRange"`
+	Range                  int                                `json:"range"`
+	CastInLine             int                                `json:"castInLine"`     // bool
+	CastInDiagonal         int                                `json:"castInDiagonal"` // bool
+	CastTestLos            int                                `json:"castTestLos"`    // bool
+	CriticalHitProbability int                                `json:"criticalHitProbability"`
+	RangeCanBeBoosted      int                                `json:"rangeCanBeBoosted"` // bool
+	MaxCastPerTurn         int                                `json:"maxCastPerTurn"`
+	MaxCastPerTarget       int                                `json:"maxCastPerTarget"`
+	MinCastInterval        int                                `json:"minCastInterval"`
+	InitialCooldown        int                                `json:"initialCooldown"`
+	GlobalCooldown         int                                `json:"globalCooldown"`
+	MinPlayerLevel         int                                `json:"minPlayerLevel"`
+	Effects                []*JSONGameItemPossibleEffectUnity `json:"effects"`
+	CriticalEffect         []*JSONGameItemPossibleEffectUnity `json:"criticalEffect"`
+}
+
+func (i *JSONGameSpellLevelUnityRaw) Merge(effects []*JSONGameItemPossibleEffectUnity, criticalEffect []*JSONGameItemPossibleEffectUnity) JSONGameSpellLevelUnity {
+	return JSONGameSpellLevelUnity{
+		Id:                     i.Id,
+		SpellId:                i.SpellId,
+		Grade:                  i.Grade,
+		ApCost:                 i.ApCost,
+		MinRange:               i.MinRange,
+		Range:                  i.Range,
+		CastInLine:             i.CastInLine,
+		CastInDiagonal:         i.CastInDiagonal,
+		CastTestLos:            i.CastTestLos,
+		CriticalHitProbability: i.CriticalHitProbability,
+		RangeCanBeBoosted:      i.RangeCanBeBoosted,
+		MaxCastPerTurn:         i.MaxCastPerTurn,
+		MaxCastPerTarget:       i.MaxCastPerTarget,
+		MinCastInterval:        i.MinCastInterval,
+		InitialCooldown:        i.InitialCooldown,
+		GlobalCooldown:         i.GlobalCooldown,
+		MinPlayerLevel:         i.MinPlayerLevel,
+		Effects:                effects,
+		CriticalEffect:         criticalEffect,
+	}
+}
+
 type JSONGameAreaUnity struct {
 	Id              int                `json:"id"`
 	NameId          int                `json:"nameId"`
@@ -494,16 +565,90 @@ func (i JSONGameAreaUnity) GetID() int {
 	return i.Id
 }
 
+type JSONGameSuperAreaUnity struct {
+	Id          int `json:"id"`
+	NameId      int `json:"nameId"`
+	WorldmapId  int `json:"worldmapId"`
+	HasWorldMap int `json:"hasWorldMap"` // bool
+}
+
+func (i JSONGameSuperAreaUnity) GetID() int {
+	return i.Id
+}
+
+type JSONGameSkillUnity struct {
+	Id                    int                        `json:"id"`
+	NameId                int                        `json:"nameId"`
+	ParentJobId           int                        `json:"parentJobId"`
+	IsForgemagus          int                        `json:"isForgemagus"` // bool
+	ModifiableItemTypeIds JSONGameUnityAnkamaIdArray `json:"modifiableItemTypeIds"`
+	GatheredRessourceItem int                        `json:"gatheredRessourceItem"`
+	CraftableItemIds      JSONGameUnityAnkamaIdArray `json:"craftableItemIds"`
+	LevelMin              int                        `json:"levelMin"`
+}
+
+func (i JSONGameSkillUnity) GetID() int {
+	return i.Id
+}
+
+type JSONGameMonsterDropUnity struct {
+	DropId               int     `json:"dropId"`
+	MonsterId            int     `json:"monsterId"`
+	ObjectId             int     `json:"objectId"`
+	PercentDropForGrade1 float64 `json:"percentDropForGrade1"`
+	PercentDropForGrade2 float64 `json:"percentDropForGrade2"`
+	PercentDropForGrade3 float64 `json:"percentDropForGrade3"`
+	PercentDropForGrade4 float64 `json:"percentDropForGrade4"`
+	PercentDropForGrade5 float64 `json:"percentDropForGrade5"`
+	Count                int     `json:"count"`
+	Criteria             string  `json:"criteria"`
+	HasCriteria          int     `json:"hasCriteria"` // bool
+}
+
+type JSONGameMonsterUnity struct {
+	Id                int                                          `json:"id"`
+	NameId            int                                          `json:"nameId"`
+	GfxId             int                                          `json:"gfxId"`
+	Race              int                                          `json:"race"`
+	Grades            JSONGameUnityArray[JSONGameMonsterGrade]     `json:"grades"`
+	Drops             JSONGameUnityArray[JSONGameMonsterDropUnity] `json:"drops"`
+	Subareas          JSONGameUnityAnkamaIdArray                   `json:"subareas"`
+	FavoriteSubareaId int                                          `json:"favoriteSubareaId"`
+	IsBoss            int                                          `json:"isBoss"`         // bool
+	IsMiniBoss        int                                          `json:"isMiniBoss"`     // bool
+	IsQuestMonster    int                                          `json:"isQuestMonster"` // bool
+}
+
+func (i JSONGameMonsterUnity) GetID() int {
+	return i.Id
+}
+
+type JSONGameMonsterRaceUnity struct {
+	Id          int                        `json:"id"`
+	SuperRaceId int                        `json:"superRaceId"`
+	NameId      int                        `json:"nameId"`
+	Monsters    JSONGameUnityAnkamaIdArray `json:"monsters"`
+}
+
+func (i JSONGameMonsterRaceUnity) GetID() int {
+	return i.Id
+}
+
 type JSONGameDataUnity struct {
-	Items     map[int]JSONGameItemUnity
-	Sets      map[int]JSONGameSetUnity
-	ItemTypes map[int]JSONGameItemTypeUnity
-	effects   map[int]JSONGameEffectUnity
-	bonuses   map[int]JSONGameBonusUnity
-	Recipes   map[int]JSONGameRecipeUnity
-	spells    map[int]JSONGameSpellUnity
-	//spellTypes       map[int]JSONGameSpellType
+	Items            map[int]JSONGameItemUnity
+	Sets             map[int]JSONGameSetUnity
+	ItemTypes        map[int]JSONGameItemTypeUnity
+	effects          map[int]JSONGameEffectUnity
+	bonuses          map[int]JSONGameBonusUnity
+	Recipes          map[int]JSONGameRecipeUnity
+	spells           map[int]JSONGameSpellUnity
+	spellTypes       map[int]JSONGameSpellType
+	spellLevels      map[int]JSONGameSpellLevelUnity
 	areas            map[int]JSONGameAreaUnity
+	superAreas       map[int]JSONGameSuperAreaUnity
+	subAreas         map[int]JSONGameSubArea
+	emotes           map[int]JSONGameEmote
+	achievements     map[int]JSONGameAchievement
 	Mounts           map[int]JSONGameMountUnity
 	classes          map[int]JSONGameBreedUnity
 	MountFamilys     map[int]JSONGameMountFamilyUnity
@@ -515,4 +660,8 @@ type JSONGameDataUnity struct {
 	questStepRewards map[int]JSONGameQuestStepRewardsUnity
 	questCategories  map[int]JSONGameQuestCategoryUnity
 	almanaxCalendars map[int]JSONGameAlamanaxCalendarUnity
+	monsters         map[int]JSONGameMonsterUnity
+	monsterRaces     map[int]JSONGameMonsterRaceUnity
+	jobs             map[int]JSONGameJob
+	skills           map[int]JSONGameSkillUnity
 }
@@ -2,67 +2,77 @@ package dodumap
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/charmbracelet/log"
 )
 
-func ParseRawDataPartUnityMulti[T HasId, A any](fileSource string, dir string, selfType string, otherType *string) JsonGameUnityRefLookup[T, A] {
-	file, err := os.ReadFile(filepath.Join(dir, fileSource))
+// unityRefFile is the layout of a serialized Unity ScriptableObject export.
+type unityRefFile struct {
+	References struct {
+		Version int `json:"version"`
+		RefIds  []struct {
+			Rid  string `json:"rid"`
+			Type struct {
+				Class string `json:"class"`
+			}
+			Data json.RawMessage // depends on class
+		} `json:"RefIds"`
+	} `json:"references"`
+}
+
+var unityRidRegex = regexp.MustCompile(`"rid": (-?\d+)`)
+
+func readUnityRefFile(fileSource string, fsys fs.FS) (unityRefFile, error) {
+	var fileJson unityRefFile
+	file, err := fs.ReadFile(fsys, fileSource)
 	if err != nil {
-		log.Fatal(err)
+		return fileJson, newDataError(fileSource, -1, "", err)
 	}
 	fileStr := CleanJSON(string(file))
 
-	pattern := `"rid": (-?\d+)`
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		log.Fatal("Error compiling regex:", err)
-	}
 	replaceFunc := func(match string) string {
-		return re.ReplaceAllString(match, `"rid": "$1"`)
-	}
-	fileStr = re.ReplaceAllStringFunc(fileStr, replaceFunc)
-
-	var fileJson struct {
-		References struct {
-			Version int `json:"version"`
-			RefIds  []struct {
-				Rid  string `json:"rid"`
-				Type struct {
-					Class string `json:"class"`
-				}
-				Data any // depends on class
-			} `json:"RefIds"`
-		} `json:"references"`
+		return unityRidRegex.ReplaceAllString(match, `"rid": "$1"`)
 	}
+	fileStr = unityRidRegex.ReplaceAllStringFunc(fileStr, replaceFunc)
+
 	err = json.Unmarshal([]byte(fileStr), &fileJson)
 	if err != nil {
-		log.Fatal(err)
+		return fileJson, decodeError(fileSource, -1, err)
 	}
+	return fileJson, nil
+}
 
+func ParseRawDataPartUnityMulti[T HasId, A any](fileSource string, fsys fs.FS, selfType string, otherType *string) (JsonGameUnityRefLookup[T, A], error) {
 	itemsAnkamaIdLookup := make(map[int]T)
 	itemsRefIdLookup := make(map[int64]A)
+	out := JsonGameUnityRefLookup[T, A]{itemsRefIdLookup, itemsAnkamaIdLookup}
+
+	fileJson, err := readUnityRefFile(fileSource, fsys)
+	if err != nil {
+		return out, err
+	}
+
+	var errs []error
 	for _, entry := range fileJson.References.RefIds {
 		rid, err := strconv.ParseInt(entry.Rid, 10, 64)
 		if err != nil {
-			log.Fatalf("Error parsing rid: %v", err)
+			errs = append(errs, newDataError(fileSource, rawEntityId(entry.Data), "rid", err))
+			continue
 		}
 		entryType := entry.Type.Class
 
 		if otherType != nil && entryType == *otherType {
 			var item A
-			payloadBytes, err := json.Marshal(entry.Data)
-			if err != nil {
-				log.Fatalf("Error marshaling to bytes: %v", err)
-			}
-			err = json.Unmarshal(payloadBytes, &item)
+			err = json.Unmarshal(entry.Data, &item)
 			if err != nil {
+				errs = append(errs, decodeError(fileSource, rawEntityId(entry.Data), err))
 				continue
 			}
 
@@ -72,13 +82,10 @@ func ParseRawDataPartUnityMulti[T HasId, A any](fileSource string, dir string, s
 
 		if selfType == "*" || entryType == selfType {
 			var item T
-			payloadBytes, err := json.Marshal(entry.Data)
+			err = json.Unmarshal(entry.Data, &item)
 			if err != nil {
-				log.Fatalf("Error marshaling to bytes: %v", err)
-			}
-			err = json.Unmarshal(payloadBytes, &item)
-			if err != nil {
-				log.Fatalf("Error unmarshaling to item: %v", err)
+				errs = append(errs, decodeError(fileSource, rawEntityId(entry.Data), err))
+				continue
 			}
 
 			itemsAnkamaIdLookup[item.GetID()] = item
@@ -90,67 +97,67 @@ func ParseRawDataPartUnityMulti[T HasId, A any](fileSource string, dir string, s
 		}
 	}
 
-	out := JsonGameUnityRefLookup[T, A]{itemsRefIdLookup, itemsAnkamaIdLookup}
-	return out
+	return out, errors.Join(errs...)
 }
 
-func ParseRawDataPartUnity[T HasId](fileSource string, result chan map[int]T, dir string) {
-	file, err := os.ReadFile(filepath.Join(dir, fileSource))
-	if err != nil {
-		log.Fatal(err)
-	}
-	fileStr := CleanJSON(string(file))
-
-	pattern := `"rid": (-?\d+)`
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		log.Fatal("Error compiling regex:", err)
-	}
-	replaceFunc := func(match string) string {
-		return re.ReplaceAllString(match, `"rid": "$1"`)
-	}
-	fileStr = re.ReplaceAllStringFunc(fileStr, replaceFunc)
-
-	var fileJson struct {
-		References struct {
-			Version int `json:"version"`
-			RefIds  []struct {
-				Rid  string `json:"rid"`
-				Type struct {
-					Class string `json:"class"`
-				}
-				Data any // depends on class
-			} `json:"RefIds"`
-		} `json:"references"`
-	}
-	err = json.Unmarshal([]byte(fileStr), &fileJson)
+func ParseRawDataPartUnity[T HasId](fileSource string, result chan map[int]T, errs chan error, fsys fs.FS) {
+	itemsAnkamaIdLookup := make(map[int]T)
+	fileJson, err := readUnityRefFile(fileSource, fsys)
 	if err != nil {
-		log.Fatal(err)
+		result <- itemsAnkamaIdLookup
+		errs <- err
+		return
 	}
 
-	itemsAnkamaIdLookup := make(map[int]T)
+	var entryErrs []error
 	for _, entry := range fileJson.References.RefIds {
 		var item T
-		payloadBytes, err := json.Marshal(entry.Data)
-		if err != nil {
-			log.Fatalf("Error marshaling to bytes: %v", err)
-		}
-		err = json.Unmarshal(payloadBytes, &item)
+		err = json.Unmarshal(entry.Data, &item)
 		if err != nil {
-			fmt.Println(err)
+			entryErrs = append(entryErrs, decodeError(fileSource, rawEntityId(entry.Data), err))
 			continue
 		}
 		itemsAnkamaIdLookup[item.GetID()] = item
 	}
 
 	result <- itemsAnkamaIdLookup
+	errs <- errors.Join(entryErrs...)
+}
+
+// resolveUnityRefs replaces the references to possible effects with the effects from the lookup.
+// The reference "-2" is Unitys null value and is kept as nil, just like references that can not be resolved.
+func resolveUnityRefs(refs []JsonGameUnityRef, lookup map[int64]JSONGameItemPossibleEffectUnity, fileSource string, entityId int) ([]*JSONGameItemPossibleEffectUnity, error) {
+	var errs []error
+	mappedPossibleEffects := make([]*JSONGameItemPossibleEffectUnity, 0)
+	for _, possibleEffectRef := range refs {
+		var possibleEffect *JSONGameItemPossibleEffectUnity = nil
+		if possibleEffectRef.Ref != "-2" {
+			res, err := strconv.ParseInt(possibleEffectRef.Ref, 10, 64)
+			if err != nil {
+				errs = append(errs, newDataError(fileSource, entityId, "rid", err))
+			} else if existingEffect, ok := lookup[res]; ok {
+				possibleEffect = &existingEffect
+			} else {
+				errs = append(errs, newDataError(fileSource, entityId, "rid", fmt.Errorf("effect %d: %w", res, ErrMissingReference)))
+			}
+		}
+		mappedPossibleEffects = append(mappedPossibleEffects, possibleEffect)
+	}
+	return mappedPossibleEffects, errors.Join(errs...)
 }
 
 type HasMerge[A any, B any] interface {
 	Merge(other B) A
 }
 
-func ParseRawDataUnity(dir string) *JSONGameDataUnity {
+// ParseRawDataUnity loads all Dofus 3 game data files from dir. See ParseRawDataUnityFS.
+func ParseRawDataUnity(dir string) (*JSONGameDataUnity, error) {
+	return ParseRawDataUnityFS(os.DirFS(dir))
+}
+
+// ParseRawDataUnityFS loads all Dofus 3 game data files from the root of fsys. Like ParseRawDataFS, it always
+// returns the data that could be parsed next to the joined errors.
+func ParseRawDataUnityFS(fsys fs.FS) (*JSONGameDataUnity, error) {
 	var data JSONGameDataUnity
 	npcsChan := make(chan map[int]JSONGameNPCUnity)
 	itemChan := make(chan map[int]JSONGameItemUnity)
@@ -160,7 +167,8 @@ func ParseRawDataUnity(dir string) *JSONGameDataUnity {
 	itemBonusesChan := make(chan map[int]JSONGameBonusUnity)
 	itemRecipesChang := make(chan map[int]JSONGameRecipeUnity)
 	spellsChan := make(chan map[int]JSONGameSpellUnity)
-	//spellTypesChan := make(chan map[int]JSONGameSpellType)
+	spellTypesChan := make(chan map[int]JSONGameSpellType)
+	spellLevelsChan := make(chan map[int]JSONGameSpellLevelUnity)
 	areasChan := make(chan map[int]JSONGameAreaUnity)
 	mountsChan := make(chan map[int]JSONGameMountUnity)
 
@@ -174,135 +182,157 @@ func ParseRawDataUnity(dir string) *JSONGameDataUnity {
 	questStepRewardsChan := make(chan map[int]JSONGameQuestStepRewardsUnity)
 	questCategoriesChan := make(chan map[int]JSONGameQuestCategoryUnity)
 	almanaxCalendarsChan := make(chan map[int]JSONGameAlamanaxCalendarUnity)
+	monstersChan := make(chan map[int]JSONGameMonsterUnity)
+	monsterRacesChan := make(chan map[int]JSONGameMonsterRaceUnity)
+	jobsChan := make(chan map[int]JSONGameJob)
+	skillsChan := make(chan map[int]JSONGameSkillUnity)
+	superAreasChan := make(chan map[int]JSONGameSuperAreaUnity)
+	subAreasChan := make(chan map[int]JSONGameSubArea)
+	emotesChan := make(chan map[int]JSONGameEmote)
+	achievementsChan := make(chan map[int]JSONGameAchievement)
+	errs := make(chan error) // every part sends its data first, so collectErrors only starts after them
+	parts := 0
+	start := func(part func()) {
+		parts++
+		go part()
+	}
 
-	go func() {
-		ParseRawDataPartUnity("npcs.json", npcsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("mount_family.json", mountFamilyChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("breeds.json", breedsChan, dir)
-	}()
-	go func() {
+	start(func() {
+		ParseRawDataPartUnity("npcs.json", npcsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("mount_family.json", mountFamilyChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("breeds.json", breedsChan, errs, fsys)
+	})
+	start(func() {
 		possibleEffectInstance := "EffectInstanceDice"
-		mountLookup := ParseRawDataPartUnityMulti[JSONGameMountUnityRaw, JSONGameItemPossibleEffectUnity]("mounts.json", dir, "MountData", &possibleEffectInstance)
+		mountLookup, err := ParseRawDataPartUnityMulti[JSONGameMountUnityRaw, JSONGameItemPossibleEffectUnity]("mounts.json", fsys, "MountData", &possibleEffectInstance)
+		mountErrs := []error{err}
 		mounts := make(map[int]JSONGameMountUnity)
 		for _, mount := range mountLookup.AnkamaId {
-			mappedPossibleEffects := make([]*JSONGameItemPossibleEffectUnity, 0)
-			for _, possibleEffectRef := range mount.Effects.Array {
-				var possibleEffect *JSONGameItemPossibleEffectUnity = nil
-				if possibleEffectRef.Ref != "-2" {
-					res, err := strconv.ParseInt(possibleEffectRef.Ref, 10, 64)
-					if err != nil {
-						log.Fatal("Mount parsing", "err", err)
-					}
-					existingEffect := mountLookup.Ref[res]
-					possibleEffect = &existingEffect
-				}
-				mappedPossibleEffects = append(mappedPossibleEffects, possibleEffect)
-			}
+			mappedPossibleEffects, err := resolveUnityRefs(mount.Effects.Array, mountLookup.Ref, "mounts.json", mount.Id)
+			mountErrs = append(mountErrs, err)
 			mergedMount := mount.Merge(mappedPossibleEffects)
 			mounts[mount.Id] = mergedMount
 		}
 		mountsChan <- mounts
-		return
-	}()
-	go func() {
-		ParseRawDataPartUnity("areas.json", areasChan, dir)
-	}()
-	/*go func() {
-		ParseRawDataPartUnity("spell_types.json", spellTypesChan, dir)
-	}()*/
-	go func() {
-		ParseRawDataPartUnity("spells.json", spellsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("recipes.json", itemRecipesChang, dir)
-	}()
-	go func() {
+		errs <- errors.Join(mountErrs...)
+	})
+	start(func() {
+		ParseRawDataPartUnity("areas.json", areasChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("spell_types.json", spellTypesChan, errs, fsys)
+	})
+	start(func() {
+		possibleEffectInstance := "EffectInstanceDice"
+		levelLookup, err := ParseRawDataPartUnityMulti[JSONGameSpellLevelUnityRaw, JSONGameItemPossibleEffectUnity]("spell_levels.json", fsys, "SpellLevelData", &possibleEffectInstance)
+		levelErrs := []error{err}
+		levels := make(map[int]JSONGameSpellLevelUnity)
+		for _, level := range levelLookup.AnkamaId {
+			effects, err := resolveUnityRefs(level.Effects.Array, levelLookup.Ref, "spell_levels.json", level.Id)
+			levelErrs = append(levelErrs, err)
+			criticalEffect, err := resolveUnityRefs(level.CriticalEffect.Array, levelLookup.Ref, "spell_levels.json", level.Id)
+			levelErrs = append(levelErrs, err)
+			levels[level.Id] = level.Merge(effects, criticalEffect)
+		}
+		spellLevelsChan <- levels
+		errs <- errors.Join(levelErrs...)
+	})
+	start(func() {
+		ParseRawDataPartUnity("spells.json", spellsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("recipes.json", itemRecipesChang, errs, fsys)
+	})
+	start(func() {
 		possibleEffectInstance := "EffectInstanceDice"
-		itemLookup := ParseRawDataPartUnityMulti[JSONGameItemUnityRaw, JSONGameItemPossibleEffectUnity]("items.json", dir, "*", &possibleEffectInstance)
+		itemLookup, err := ParseRawDataPartUnityMulti[JSONGameItemUnityRaw, JSONGameItemPossibleEffectUnity]("items.json", fsys, "*", &possibleEffectInstance)
+		itemErrs := []error{err}
 		items := make(map[int]JSONGameItemUnity)
 		for _, item := range itemLookup.AnkamaId {
-			mappedPossibleEffects := make([]*JSONGameItemPossibleEffectUnity, 0)
-			for _, possibleEffectRef := range item.PossibleEffects.Array {
-				var possibleEffect *JSONGameItemPossibleEffectUnity = nil
-				if possibleEffectRef.Ref != "-2" {
-					res, err := strconv.ParseInt(possibleEffectRef.Ref, 10, 64)
-					if err != nil {
-						log.Fatal("Item parsing", "err", err)
-					}
-					existingEffect := itemLookup.Ref[res]
-
-					possibleEffect = &existingEffect
-				}
-				mappedPossibleEffects = append(mappedPossibleEffects, possibleEffect)
-			}
+			mappedPossibleEffects, err := resolveUnityRefs(item.PossibleEffects.Array, itemLookup.Ref, "items.json", item.Id)
+			itemErrs = append(itemErrs, err)
 			mergedItem := item.Merge(mappedPossibleEffects)
 			items[item.Id] = mergedItem
 		}
 		itemChan <- items
-		return
-	}()
-	go func() {
-		ParseRawDataPartUnity("item_types.json", itemTypeChan, dir)
-	}()
-	go func() {
+		errs <- errors.Join(itemErrs...)
+	})
+	start(func() {
+		ParseRawDataPartUnity("item_types.json", itemTypeChan, errs, fsys)
+	})
+	start(func() {
 		possibleEffectInstance := "EffectInstanceDice"
-		setLookup := ParseRawDataPartUnityMulti[JSONGameSetUnityRaw, JSONGameItemPossibleEffectUnity]("item_sets.json", dir, "ItemSetData", &possibleEffectInstance)
+		setLookup, err := ParseRawDataPartUnityMulti[JSONGameSetUnityRaw, JSONGameItemPossibleEffectUnity]("item_sets.json", fsys, "ItemSetData", &possibleEffectInstance)
+		setErrs := []error{err}
 		sets := make(map[int]JSONGameSetUnity)
 		for _, set := range setLookup.AnkamaId {
 			mappedPossibleEffects := make([][]*JSONGameItemPossibleEffectUnity, 0)
 			for _, possibleEffectsRef := range set.Effects.Array {
-				mappedPossibleEffectsInner := make([]*JSONGameItemPossibleEffectUnity, 0)
-				for _, possibleEffectRef := range possibleEffectsRef.Values.Array {
-					var possibleEffect *JSONGameItemPossibleEffectUnity = nil
-					if possibleEffectRef.Ref != "-2" {
-						res, err := strconv.ParseInt(possibleEffectRef.Ref, 10, 64)
-						if err != nil {
-							log.Fatal("Set parsing", "err", err)
-						}
-						existingEffect := setLookup.Ref[res]
-						possibleEffect = &existingEffect
-					}
-					mappedPossibleEffectsInner = append(mappedPossibleEffectsInner, possibleEffect)
-				}
+				mappedPossibleEffectsInner, err := resolveUnityRefs(possibleEffectsRef.Values.Array, setLookup.Ref, "item_sets.json", set.Id)
+				setErrs = append(setErrs, err)
 				mappedPossibleEffects = append(mappedPossibleEffects, mappedPossibleEffectsInner)
 			}
 			mergedSet := set.Merge(mappedPossibleEffects)
 			sets[set.Id] = mergedSet
 		}
 		itemSetsChan <- sets
-		return
-	}()
-	go func() {
-		ParseRawDataPartUnity("bonuses.json", itemBonusesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("effects.json", itemEffectsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("titles.json", titlesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("quests.json", questsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("quest_objectives.json", questObjectivesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("quest_step_rewards.json", questStepRewardsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("quest_categories.json", questCategoriesChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("almanax.json", almanaxCalendarsChan, dir)
-	}()
-	go func() {
-		ParseRawDataPartUnity("quest_steps.json", questStepsChan, dir)
-	}()
+		errs <- errors.Join(setErrs...)
+	})
+	start(func() {
+		ParseRawDataPartUnity("bonuses.json", itemBonusesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("effects.json", itemEffectsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("titles.json", titlesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("quests.json", questsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("quest_objectives.json", questObjectivesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("quest_step_rewards.json", questStepRewardsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("quest_categories.json", questCategoriesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("almanax.json", almanaxCalendarsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("quest_steps.json", questStepsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("monsters.json", monstersChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("monster_races.json", monsterRacesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("jobs.json", jobsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("skills.json", skillsChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("super_areas.json", superAreasChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("sub_areas.json", subAreasChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("emotes.json", emotesChan, errs, fsys)
+	})
+	start(func() {
+		ParseRawDataPartUnity("achievements.json", achievementsChan, errs, fsys)
+	})
 
 	data.bonuses = <-itemBonusesChan
 	close(itemBonusesChan)
@@ -322,8 +352,11 @@ func ParseRawDataUnity(dir string) *JSONGameDataUnity {
 	data.spells = <-spellsChan
 	close(spellsChan)
 
-	//data.spellTypes = <-spellTypesChan
-	//close(spellTypesChan)
+	data.spellTypes = <-spellTypesChan
+	close(spellTypesChan)
+
+	data.spellLevels = <-spellLevelsChan
+	close(spellLevelsChan)
 
 	data.areas = <-areasChan
 	close(areasChan)
@@ -364,301 +397,113 @@ func ParseRawDataUnity(dir string) *JSONGameDataUnity {
 	data.Items = <-itemChan
 	close(itemChan)
 
-	return &data
+	data.monsters = <-monstersChan
+	close(monstersChan)
+
+	data.monsterRaces = <-monsterRacesChan
+	close(monsterRacesChan)
+
+	data.jobs = <-jobsChan
+	close(jobsChan)
+
+	data.skills = <-skillsChan
+	close(skillsChan)
+
+	data.superAreas = <-superAreasChan
+	close(superAreasChan)
+
+	data.subAreas = <-subAreasChan
+	close(subAreasChan)
+
+	data.emotes = <-emotesChan
+	close(emotesChan)
+
+	data.achievements = <-achievementsChan
+	close(achievementsChan)
+
+	return &data, collectErrors(errs, parts)
 }
 
-func ParseRawLanguagesUnity(dir string) map[string]LangDictUnity {
+// ParseRawLanguagesUnity loads the translations of all LanguagesUnity from dir, see ParseRawLanguages.
+func ParseRawLanguagesUnity(dir string) (map[string]LangDictUnity, error) {
+	return ParseRawLanguagesUnityFS(os.DirFS(dir))
+}
+
+// ParseRawLanguagesUnityFS loads the translations of all LanguagesUnity from fsys, see ParseRawLanguagesFS.
+func ParseRawLanguagesUnityFS(fsys fs.FS) (map[string]LangDictUnity, error) {
 	data := make(map[string]LangDictUnity)
+	var errs []error
 	for _, lang := range LanguagesUnity {
-		data[lang] = ParseLangDictUnity(lang, dir)
+		dict, err := ParseLangDictUnityFS(lang, fsys)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		data[lang] = dict
 	}
-	return data
+	return data, errors.Join(errs...)
 }
 
-func ParseLangDictUnity(langCode string, dir string) LangDictUnity {
+// ParseLangDictUnity reads the Dofus 3 translations for langCode from dir/languages.
+func ParseLangDictUnity(langCode string, dir string) (LangDictUnity, error) {
+	return ParseLangDictUnityFS(langCode, os.DirFS(dir))
+}
+
+// ParseLangDictUnityFS reads the Dofus 3 translations for langCode from languages/ in fsys.
+func ParseLangDictUnityFS(langCode string, fsys fs.FS) (LangDictUnity, error) {
 	var err error
 
-	dataPath := filepath.Join(dir, "languages")
+	fileSource := path.Join("languages", langCode+".json")
 	var data LangDictUnity
 	data.Texts = make(map[int]string)
 
-	langFile, err := os.ReadFile(filepath.Join(dataPath, langCode+".json"))
+	langFile, err := fs.ReadFile(fsys, fileSource)
 	if err != nil {
-		log.Fatal(err)
+		return data, newDataError(fileSource, -1, "", err)
 	}
 
 	langFileStr := CleanJSON(string(langFile))
 	var langJson JSONLangDictUnity
 	err = json.Unmarshal([]byte(langFileStr), &langJson)
 	if err != nil {
-		log.Fatal(err)
+		return data, decodeError(fileSource, -1, err)
 	}
 
+	var errs []error
 	for key, value := range langJson.Texts {
 		keyParsed, err := strconv.Atoi(key)
 		if err != nil {
-			log.Fatal("langDict", err)
+			errs = append(errs, newDataError(fileSource, -1, "entries."+key, err))
+			continue
 		}
 		data.Texts[keyParsed] = value
 	}
 
-	return data
+	return data, errors.Join(errs...)
 }
 
-func ParseEffectsUnity(data *JSONGameDataUnity, allEffects [][]*JSONGameItemPossibleEffectUnity, langs *map[string]LangDictUnity) [][]*MappedMultilangEffect {
-	var mappedAllEffects [][]*MappedMultilangEffect
-	for _, effects := range allEffects {
-		var mappedEffects []*MappedMultilangEffect
-		for _, effect := range effects {
-
-			if effect == nil {
-				mappedEffects = append(mappedEffects, nil)
-				continue
-			}
-
-			var mappedEffect MappedMultilangEffect
-			currentEffect := data.effects[effect.EffectId]
-
-			numIsSpell := false
-			if strings.Contains((*langs)["de"].Texts[currentEffect.DescriptionId], "Zauberspruchs #1") || strings.Contains((*langs)["de"].Texts[currentEffect.DescriptionId], "Zaubers #1") {
-				numIsSpell = true
-			}
-
-			isTitle := false
-			if strings.Contains((*langs)["en"].Texts[currentEffect.DescriptionId], "Title:") {
-				isTitle = true
-			}
-
-			mappedEffect.Type = make(map[string]string)
-			mappedEffect.Templated = make(map[string]string)
-			var minMaxRemove int
-			var frNumSigned int = 2  // unset
-			var frSideSigned int = 2 // unset
-			for _, lang := range LanguagesUnity {
-				var diceNum int
-				var diceSide int
-				var value int
-
-				diceNum = effect.MinimumValue
-
-				diceSide = effect.MaximumValue
-
-				value = effect.Value
-
-				effectName := (*langs)[lang].Texts[currentEffect.DescriptionId]
-				if lang == "de" {
-					effectName = strings.ReplaceAll(effectName, "{~ps}{~zs}", "") // german has error in template
-				}
-
-				if effectName == "#1" { // is spell description from dicenum 1
-					effectName = "-special spell-"
-					mappedEffect.Min = 0
-					mappedEffect.Max = 0
-					mappedEffect.Type[lang] = effectName
-					mappedEffect.Templated[lang] = (*langs)[lang].Texts[data.spells[diceNum].DescriptionId]
-					mappedEffect.IsMeta = true
-				} else {
-					templatedName := effectName
-					var useDice bool
-					if currentEffect.UseDice == 0 {
-						useDice = false
-					} else {
-						useDice = true
-					}
-					templatedName, minMaxRemove = NumSpellFormatterUnity(templatedName, lang, data, langs, &diceNum, &diceSide, &value, currentEffect.DescriptionId, numIsSpell, useDice, &frNumSigned, &frSideSigned)
-					if templatedName == "" { // found effect that should be discarded for now
-						break
-					}
-					templatedName = SingularPluralFormatterUnity(templatedName, effect.MinimumValue, lang)
-
-					if isTitle { // titles are Title: 0 after formatting; TODO move this into the NumSpellFormatter
-						maleTitleNum, err := strconv.Atoi(data.titles[diceNum].NameMaleId) // TODO male default, idk how to make it neutral yet
-						var replTitle string
-						if err != nil {
-							log.Warn("EffectParsing", "InvalidTitleId", data.titles[diceNum].Id, "PossibleId", diceNum)
-							replTitle = "-invalid-"
-						} else {
-							replTitle = (*langs)[lang].Texts[maleTitleNum]
-						}
-						templatedName = strings.ReplaceAll(templatedName, "0", replTitle)
-					}
-
-					effectName = DeleteDamageFormatterUnity(effectName)
-					effectName = SingularPluralFormatterUnity(effectName, 1, lang) // singularize the effect name for comparisons
-
-					if isTitle {
-						mappedEffect.Min = 0
-						mappedEffect.Max = 0
-						mappedEffect.IsMeta = true
-					} else {
-						mappedEffect.Min = diceNum
-						mappedEffect.Max = diceSide
-						mappedEffect.IsMeta = false
-					}
-					mappedEffect.Max = diceSide
-					mappedEffect.Type[lang] = effectName
-					mappedEffect.Templated[lang] = templatedName
-				}
-
-				if lang == "en" && mappedEffect.Type[lang] == "" {
-					break
-				}
-			}
-
-			if mappedEffect.Type["en"] == "()" || mappedEffect.Type["en"] == "" {
-				// this happens way too often but we can't do anything about it
-				//log.Warn("Effect", "emtpy_type", mappedEffect.Type["en"])
-				mappedEffects = append(mappedEffects, nil)
-				continue
-			}
-
-			if currentEffect.UseInFight == 0 {
-				mappedEffect.Active = false
-			} else {
-				mappedEffect.Active = true
-			}
-			searchTypeEn := mappedEffect.Type["en"]
-			// TODO this is a hack since the beta has probably a typo that changed the ID of the element
-			if searchTypeEn == "Air damage" {
-				searchTypeEn = "Air Damage"
-			}
-			if mappedEffect.Active {
-				searchTypeEn += " (Active)"
-			}
-			key, foundKey := PersistedElements.Entries.GetKey(searchTypeEn)
-			if foundKey {
-				mappedEffect.ElementId = key.(int)
-			} else {
-				mappedEffect.ElementId = PersistedElements.NextId
-				PersistedElements.Entries.Put(PersistedElements.NextId, searchTypeEn)
-				PersistedElements.NextId++
-			}
-
-			mappedEffect.MinMaxIrrelevant = minMaxRemove
-
-			mappedEffects = append(mappedEffects, &mappedEffect)
-		}
-		mappedAllEffects = append(mappedAllEffects, mappedEffects)
+func ParseEffectsUnity(data *JSONGameDataUnity, allEffects [][]*JSONGameItemPossibleEffectUnity, langs *map[string]LangDictUnity, elements *IDRegistry) ([][]*MappedMultilangEffect, error) {
+	converted := make([][]*JSONGameItemPossibleEffect, len(allEffects))
+	for i, effects := range allEffects {
+		converted[i] = convertPossibleEffectsUnity(effects)
 	}
-	if len(mappedAllEffects) == 0 {
-		return nil
-	}
-	return mappedAllEffects
+	return parseEffects(NewGameDataUnity(data, *langs), converted, elements, true)
 }
 
-func atomicConditionUnity(expression string, langs *map[string]LangDictUnity, data *JSONGameDataUnity) (bool, MappedMultilangCondition) {
-	operators := []string{"<", ">", "=", "!"}
-
-	var out MappedMultilangCondition
-	out.Templated = make(map[string]string)
-
-	foundCond := false
-	for _, operator := range operators { // try every known operator against it
-		if strings.Contains(expression, operator) {
-			foundConditionElement := ConditionWithOperatorUnity(expression, operator, langs, &out, data)
-			if foundConditionElement {
-				foundCond = true
-				break
-			}
-		}
-	}
-
-	return foundCond, out
-}
-
-func removeUnsupportedExpressionsUnity(node *ConditionTreeNode, langs *map[string]LangDictUnity, data *JSONGameDataUnity) *ConditionTreeNode {
-	if node == nil {
-		return nil
-	}
-
-	// If the node is an operand and not supported, return nil
-	validCond, _ := atomicConditionUnity(node.Value, langs, data)
-	if node.Type == Operand && !validCond {
-		return nil
-	}
-
-	// Process children
-	var validChildren []*ConditionTreeNode
-	for _, child := range node.Children {
-		processedChild := removeUnsupportedExpressionsUnity(child, langs, data)
-		if processedChild != nil {
-			validChildren = append(validChildren, processedChild)
-		}
-	}
-	node.Children = validChildren
-
-	return node
+func ParseConditionUnity(condition string, langs *map[string]LangDictUnity, data *JSONGameDataUnity, elements *IDRegistry) (*ConditionTreeNodeMapped, error) {
+	_, tree, err := parseCondition(condition, NewGameDataUnity(data, *langs), elements)
+	return tree, err
 }
 
-func ParseConditionUnity(condition string, langs *map[string]LangDictUnity, data *JSONGameDataUnity) *ConditionTreeNodeMapped {
-	if condition == "" || (!strings.Contains(condition, "&") && !strings.Contains(condition, "|") && !strings.Contains(condition, "<") && !strings.Contains(condition, ">")) {
-		return nil
-	}
-
-	// parse into ast
-	tree := ParseExpression(condition)
-
-	// strip tree to only known conditions
-	tree = removeUnsupportedExpressionsUnity(tree, langs, data)
-	tree = simplifyTree(tree)
-
-	if tree == nil {
-		return nil
-	}
-
-	// convert to mapped tree
-	mappedTree := new(*ConditionTreeNodeMapped)
-	buildMappedConditionTreeUnity(mappedTree, tree, langs, data)
-
-	if *mappedTree == nil {
-		log.Fatal("mapped tree is nil")
-	}
-
-	return *mappedTree
+// ParseConditionKeepUnknownUnity is ParseConditionUnity with raw conditions, see ParseConditionKeepUnknown.
+func ParseConditionKeepUnknownUnity(condition string, langs *map[string]LangDictUnity, data *JSONGameDataUnity, elements *IDRegistry) (*ConditionTreeNodeMapped, error) {
+	_, tree, err := parseCondition(condition, withUnknownCriteria(NewGameDataUnity(data, *langs)), elements)
+	return tree, err
 }
 
-func buildMappedConditionTreeUnity(out **ConditionTreeNodeMapped, root *ConditionTreeNode, langs *map[string]LangDictUnity, data *JSONGameDataUnity) {
-	if root == nil {
-		return
-	}
-
-	if root.Type == Operand {
-		foundCond, mappedCond := atomicConditionUnity(root.Value, langs, data)
-		if !foundCond {
-			log.Fatal("condition not found, should be handled before")
-		}
-
-		if *out == nil {
-			*out = new(ConditionTreeNodeMapped)
-		}
-
-		(*out).Value = &mappedCond
-		(*out).IsOperand = true
-		return
-	} else if root.Type == Operator {
-		if *out == nil {
-			*out = new(ConditionTreeNodeMapped)
-		}
-		(*out).IsOperand = false
-		if root.Value == "&" {
-			(*out).Relation = new(string)
-			*(*out).Relation = "and"
-		} else if root.Value == "|" {
-			(*out).Relation = new(string)
-			*(*out).Relation = "or"
-		} else {
-			log.Fatal("unknown operator")
-		}
-		(*out).Children = make([]*ConditionTreeNodeMapped, len(root.Children))
-		for i, child := range root.Children {
-			childOut := new(*ConditionTreeNodeMapped)
-			buildMappedConditionTreeUnity(childOut, child, langs, data)
-			(*out).Children[i] = *childOut
-		}
-		return
-	} else {
-		log.Fatal("unknown node type")
-	}
+// ParseQuestConditionUnity is ParseConditionUnity for quest start criteria, see ParseQuestCondition.
+func ParseQuestConditionUnity(condition string, langs *map[string]LangDictUnity, data *JSONGameDataUnity, elements *IDRegistry) (*ConditionTreeNodeMapped, error) {
+	_, tree, err := parseCondition(condition, withQuestCriteria(NewGameDataUnity(data, *langs)), elements)
+	return tree, err
 }
 
 func ParseItemComboUnity(effects [][]*MappedMultilangEffect) map[int][]MappedMultilangEffect {
@@ -0,0 +1,182 @@
+package dodumap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+// DefaultRegistryURL is where doduda publishes the persisted element and item type ids.
+const DefaultRegistryURL = "https://raw.githubusercontent.com/dofusdude/doduda/main/persistent"
+
+// ErrNotModified is returned by a RegistryFetcher when the remote file still matches the given etag.
+var ErrNotModified = errors.New("registry file not modified")
+
+// RegistryFetcher downloads a persisted id file like "elements.dofus3.main.json". etag is the
+// entity tag of the cached copy and empty when there is none.
+type RegistryFetcher interface {
+	Fetch(ctx context.Context, name string, etag string) (body []byte, newEtag string, err error)
+}
+
+// HTTPRegistryFetcher fetches registry files from BaseURL with conditional requests.
+type HTTPRegistryFetcher struct {
+	BaseURL string
+	Client  *http.Client // http.DefaultClient when nil
+}
+
+func (f *HTTPRegistryFetcher) Fetch(ctx context.Context, name string, etag string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.BaseURL+"/"+name, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, etag, ErrNotModified
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, "", err
+		}
+		return body, resp.Header.Get("ETag"), nil
+	}
+	return nil, "", fmt.Errorf("fetching %s: unexpected status %s", name, resp.Status)
+}
+
+// registryCacheMeta is stored next to every cached registry file as <name>.meta.json.
+type registryCacheMeta struct {
+	ETag   string `json:"etag"`
+	Sha256 string `json:"sha256"`
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readRegistryCache returns the cached file and its etag. A missing cache or a file that does not
+// match its checksum is reported as not ok.
+func readRegistryCache(cacheDir string, name string) ([]byte, string, bool) {
+	if cacheDir == "" {
+		return nil, "", false
+	}
+	data, err := os.ReadFile(filepath.Join(cacheDir, name))
+	if err != nil {
+		return nil, "", false
+	}
+	metaData, err := os.ReadFile(filepath.Join(cacheDir, name+".meta.json"))
+	if err != nil {
+		return nil, "", false
+	}
+	var meta registryCacheMeta
+	if json.Unmarshal(metaData, &meta) != nil || meta.Sha256 != sha256Hex(data) {
+		log.Warn("ignoring corrupt registry cache", "file", name)
+		return nil, "", false
+	}
+	return data, meta.ETag, true
+}
+
+func writeRegistryCache(cacheDir string, name string, data []byte, etag string) error {
+	if cacheDir == "" {
+		return nil
+	}
+	err := os.MkdirAll(cacheDir, 0755)
+	if err != nil {
+		return err
+	}
+	metaData, err := json.Marshal(registryCacheMeta{ETag: etag, Sha256: sha256Hex(data)})
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(cacheDir, name), data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cacheDir, name+".meta.json"), metaData, 0644)
+}
+
+// fetchRegistryFile returns the newest version of name that is available. It asks the fetcher with the
+// etag of the cached copy and falls back to the cache when the fetcher fails.
+func fetchRegistryFile(ctx context.Context, fetcher RegistryFetcher, cacheDir string, name string) ([]RegistryEntry, error) {
+	cached, etag, cacheOk := readRegistryCache(cacheDir, name)
+
+	body, newEtag, err := fetcher.Fetch(ctx, name, etag)
+	if errors.Is(err, ErrNotModified) && cacheOk {
+		return decodeRegistryFile(name, cached)
+	}
+	if err == nil {
+		var entries []RegistryEntry
+		entries, err = decodeRegistryFile(name, body)
+		if err == nil {
+			if cacheErr := writeRegistryCache(cacheDir, name, body, newEtag); cacheErr != nil {
+				log.Warn("could not cache registry file", "file", name, "err", cacheErr)
+			}
+			return entries, nil
+		}
+	}
+	if cacheOk {
+		log.Warn("using cached registry file", "file", name, "err", err)
+		return decodeRegistryFile(name, cached)
+	}
+	return nil, fmt.Errorf("fetching %s without a usable cache: %w", name, err)
+}
+
+func decodeRegistryFile(name string, data []byte) ([]RegistryEntry, error) {
+	var entries []RegistryEntry
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, decodeError(name, -1, err)
+	}
+	return entries, nil
+}
+
+// DefaultRegistryCacheDir is the user cache directory used by LoadPersistedElements when no
+// persistenceDir is given. It is empty when the platform has no cache directory.
+func DefaultRegistryCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dodumap", "persistent")
+}
+
+// LoadPersistedElementsWith loads the persisted ids through fetcher and keeps a copy of every
+// download in cacheDir, so later runs still work without network. An empty cacheDir disables the cache.
+func LoadPersistedElementsWith(ctx context.Context, fetcher RegistryFetcher, cacheDir string, release string, majorVersion int) (*PersistedIds, error) {
+	elementsName, typesName := persistedFileNames(release, majorVersion)
+
+	elements, err := fetchRegistryFile(ctx, fetcher, cacheDir, elementsName)
+	if err != nil {
+		return nil, err
+	}
+	types, err := fetchRegistryFile(ctx, fetcher, cacheDir, typesName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersistedIds{
+		Elements: newIDRegistryFromEntries(elements),
+		Types:    newIDRegistryFromEntries(types),
+	}, nil
+}
@@ -0,0 +1,334 @@
+package dodumap
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// The lookup methods return the entry with the given id and whether it exists. The All methods iterate over
+// a collection in ascending id order. Both are safe to use concurrently as long as the data is not modified.
+
+func lookup[T any](entries map[int]T, id int) (T, bool) {
+	entry, ok := entries[id]
+	return entry, ok
+}
+
+// sortedAll iterates over entries in ascending id order.
+func sortedAll[T any](entries map[int]T) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for _, id := range slices.Sorted(maps.Keys(entries)) {
+			if !yield(id, entries[id]) {
+				return
+			}
+		}
+	}
+}
+
+// Item returns the item with id.
+func (d *JSONGameData) Item(id int) (JSONGameItem, bool) {
+	return lookup(d.Items, id)
+}
+
+// AllItems iterates over all items sorted by id.
+func (d *JSONGameData) AllItems() iter.Seq2[int, JSONGameItem] {
+	return sortedAll(d.Items)
+}
+
+// Set returns the item set with id.
+func (d *JSONGameData) Set(id int) (JSONGameSet, bool) {
+	return lookup(d.Sets, id)
+}
+
+// AllSets iterates over all item sets sorted by id.
+func (d *JSONGameData) AllSets() iter.Seq2[int, JSONGameSet] {
+	return sortedAll(d.Sets)
+}
+
+// ItemType returns the item type with id.
+func (d *JSONGameData) ItemType(id int) (JSONGameItemType, bool) {
+	return lookup(d.ItemTypes, id)
+}
+
+// AllItemTypes iterates over all item types sorted by id.
+func (d *JSONGameData) AllItemTypes() iter.Seq2[int, JSONGameItemType] {
+	return sortedAll(d.ItemTypes)
+}
+
+// Effect returns the effect with id.
+func (d *JSONGameData) Effect(id int) (JSONGameEffect, bool) {
+	return lookup(d.effects, id)
+}
+
+// AllEffects iterates over all effects sorted by id.
+func (d *JSONGameData) AllEffects() iter.Seq2[int, JSONGameEffect] {
+	return sortedAll(d.effects)
+}
+
+// Bonus returns the bonus with id.
+func (d *JSONGameData) Bonus(id int) (JSONGameBonus, bool) {
+	return lookup(d.bonuses, id)
+}
+
+// AllBonuses iterates over all bonuses sorted by id.
+func (d *JSONGameData) AllBonuses() iter.Seq2[int, JSONGameBonus] {
+	return sortedAll(d.bonuses)
+}
+
+// Recipe returns the recipe with id.
+func (d *JSONGameData) Recipe(id int) (JSONGameRecipe, bool) {
+	return lookup(d.Recipes, id)
+}
+
+// AllRecipes iterates over all recipes sorted by id.
+func (d *JSONGameData) AllRecipes() iter.Seq2[int, JSONGameRecipe] {
+	return sortedAll(d.Recipes)
+}
+
+// Spell returns the spell with id.
+func (d *JSONGameData) Spell(id int) (JSONGameSpell, bool) {
+	return lookup(d.spells, id)
+}
+
+// AllSpells iterates over all spells sorted by id.
+func (d *JSONGameData) AllSpells() iter.Seq2[int, JSONGameSpell] {
+	return sortedAll(d.spells)
+}
+
+// SpellType returns the spell type with id.
+func (d *JSONGameData) SpellType(id int) (JSONGameSpellType, bool) {
+	return lookup(d.spellTypes, id)
+}
+
+// AllSpellTypes iterates over all spell types sorted by id.
+func (d *JSONGameData) AllSpellTypes() iter.Seq2[int, JSONGameSpellType] {
+	return sortedAll(d.spellTypes)
+}
+
+// SpellLevel returns the spell level with id.
+func (d *JSONGameData) SpellLevel(id int) (JSONGameSpellLevel, bool) {
+	return lookup(d.spellLevels, id)
+}
+
+// AllSpellLevels iterates over all spell levels sorted by id.
+func (d *JSONGameData) AllSpellLevels() iter.Seq2[int, JSONGameSpellLevel] {
+	return sortedAll(d.spellLevels)
+}
+
+// Area returns the area with id.
+func (d *JSONGameData) Area(id int) (JSONGameArea, bool) {
+	return lookup(d.areas, id)
+}
+
+// AllAreas iterates over all areas sorted by id.
+func (d *JSONGameData) AllAreas() iter.Seq2[int, JSONGameArea] {
+	return sortedAll(d.areas)
+}
+
+// SuperArea returns the super area with id.
+func (d *JSONGameData) SuperArea(id int) (JSONGameSuperArea, bool) {
+	return lookup(d.superAreas, id)
+}
+
+// SubArea returns the sub-area with id.
+func (d *JSONGameData) SubArea(id int) (JSONGameSubArea, bool) {
+	return lookup(d.subAreas, id)
+}
+
+// AllSubAreas iterates over all sub-areas sorted by id.
+func (d *JSONGameData) AllSubAreas() iter.Seq2[int, JSONGameSubArea] {
+	return sortedAll(d.subAreas)
+}
+
+// Emote returns the emote with id.
+func (d *JSONGameData) Emote(id int) (JSONGameEmote, bool) {
+	return lookup(d.emotes, id)
+}
+
+// Achievement returns the achievement with id.
+func (d *JSONGameData) Achievement(id int) (JSONGameAchievement, bool) {
+	return lookup(d.achievements, id)
+}
+
+// Mount returns the mount with id.
+func (d *JSONGameData) Mount(id int) (JSONGameMount, bool) {
+	return lookup(d.Mounts, id)
+}
+
+// AllMounts iterates over all mounts sorted by id.
+func (d *JSONGameData) AllMounts() iter.Seq2[int, JSONGameMount] {
+	return sortedAll(d.Mounts)
+}
+
+// MountFamily returns the mount family with id.
+func (d *JSONGameData) MountFamily(id int) (JSONGameMountFamily, bool) {
+	return lookup(d.MountFamilys, id)
+}
+
+// AllMountFamilies iterates over all mount families sorted by id.
+func (d *JSONGameData) AllMountFamilies() iter.Seq2[int, JSONGameMountFamily] {
+	return sortedAll(d.MountFamilys)
+}
+
+// Breed returns the breed with id.
+func (d *JSONGameData) Breed(id int) (JSONGameBreed, bool) {
+	return lookup(d.classes, id)
+}
+
+// AllBreeds iterates over all breeds sorted by id.
+func (d *JSONGameData) AllBreeds() iter.Seq2[int, JSONGameBreed] {
+	return sortedAll(d.classes)
+}
+
+// NPC returns the npc with id.
+func (d *JSONGameData) NPC(id int) (JSONGameNPC, bool) {
+	return lookup(d.npcs, id)
+}
+
+// AllNPCs iterates over all npcs sorted by id.
+func (d *JSONGameData) AllNPCs() iter.Seq2[int, JSONGameNPC] {
+	return sortedAll(d.npcs)
+}
+
+// Monster returns the monster with id.
+func (d *JSONGameData) Monster(id int) (JSONGameMonster, bool) {
+	return lookup(d.monsters, id)
+}
+
+// AllMonsters iterates over all monsters sorted by id.
+func (d *JSONGameData) AllMonsters() iter.Seq2[int, JSONGameMonster] {
+	return sortedAll(d.monsters)
+}
+
+// MonsterRace returns the monster race with id.
+func (d *JSONGameData) MonsterRace(id int) (JSONGameMonsterRace, bool) {
+	return lookup(d.monsterRaces, id)
+}
+
+// Job returns the job with id.
+func (d *JSONGameData) Job(id int) (JSONGameJob, bool) {
+	return lookup(d.jobs, id)
+}
+
+// AllJobs iterates over all jobs sorted by id.
+func (d *JSONGameData) AllJobs() iter.Seq2[int, JSONGameJob] {
+	return sortedAll(d.jobs)
+}
+
+// Skill returns the skill with id.
+func (d *JSONGameData) Skill(id int) (JSONGameSkill, bool) {
+	return lookup(d.skills, id)
+}
+
+// AllSkills iterates over all skills sorted by id.
+func (d *JSONGameData) AllSkills() iter.Seq2[int, JSONGameSkill] {
+	return sortedAll(d.skills)
+}
+
+// Title returns the title with id.
+func (d *JSONGameData) Title(id int) (JSONGameTitle, bool) {
+	return lookup(d.titles, id)
+}
+
+// AllTitles iterates over all titles sorted by id.
+func (d *JSONGameData) AllTitles() iter.Seq2[int, JSONGameTitle] {
+	return sortedAll(d.titles)
+}
+
+// Quest returns the quest with id.
+func (d *JSONGameData) Quest(id int) (JSONGameQuest, bool) {
+	return lookup(d.quests, id)
+}
+
+// AllQuests iterates over all quests sorted by id.
+func (d *JSONGameData) AllQuests() iter.Seq2[int, JSONGameQuest] {
+	return sortedAll(d.quests)
+}
+
+// QuestStep returns the quest step with id.
+func (d *JSONGameData) QuestStep(id int) (JSONGameQuestStep, bool) {
+	return lookup(d.questSteps, id)
+}
+
+// AllQuestSteps iterates over all quest steps sorted by id.
+func (d *JSONGameData) AllQuestSteps() iter.Seq2[int, JSONGameQuestStep] {
+	return sortedAll(d.questSteps)
+}
+
+// QuestObjective returns the quest objective with id.
+func (d *JSONGameData) QuestObjective(id int) (JSONGameQuestObjective, bool) {
+	return lookup(d.questObjectives, id)
+}
+
+// AllQuestObjectives iterates over all quest objectives sorted by id.
+func (d *JSONGameData) AllQuestObjectives() iter.Seq2[int, JSONGameQuestObjective] {
+	return sortedAll(d.questObjectives)
+}
+
+// QuestStepRewards returns the quest step rewards with id.
+func (d *JSONGameData) QuestStepRewards(id int) (JSONGameQuestStepRewards, bool) {
+	return lookup(d.questStepRewards, id)
+}
+
+// AllQuestStepRewards iterates over all quest step rewards sorted by id.
+func (d *JSONGameData) AllQuestStepRewards() iter.Seq2[int, JSONGameQuestStepRewards] {
+	return sortedAll(d.questStepRewards)
+}
+
+// QuestCategory returns the quest category with id.
+func (d *JSONGameData) QuestCategory(id int) (JSONGameQuestCategory, bool) {
+	return lookup(d.questCategories, id)
+}
+
+// AllQuestCategories iterates over all quest categories sorted by id.
+func (d *JSONGameData) AllQuestCategories() iter.Seq2[int, JSONGameQuestCategory] {
+	return sortedAll(d.questCategories)
+}
+
+// AlmanaxCalendar returns the almanax calendar with id.
+func (d *JSONGameData) AlmanaxCalendar(id int) (JSONGameAlamanaxCalendar, bool) {
+	return lookup(d.almanaxCalendars, id)
+}
+
+// AllAlmanaxCalendars iterates over all almanax calendars sorted by id.
+func (d *JSONGameData) AllAlmanaxCalendars() iter.Seq2[int, JSONGameAlamanaxCalendar] {
+	return sortedAll(d.almanaxCalendars)
+}
+
+// lookupAll returns the entries of ids that exist, in the order of ids.
+func lookupAll[T any](entries map[int]T, ids []int) []T {
+	var found []T
+	for _, id := range ids {
+		if entry, ok := entries[id]; ok {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// QuestStepsOf returns the steps of a quest in their order. Missing steps are skipped.
+func (d *JSONGameData) QuestStepsOf(questId int) []JSONGameQuestStep {
+	quest, found := d.Quest(questId)
+	if !found {
+		return nil
+	}
+	return lookupAll(d.questSteps, quest.StepIds)
+}
+
+// QuestObjectivesOf returns the objectives of a quest step in their order. Missing objectives are skipped.
+func (d *JSONGameData) QuestObjectivesOf(stepId int) []JSONGameQuestObjective {
+	step, found := d.QuestStep(stepId)
+	if !found {
+		return nil
+	}
+	return lookupAll(d.questObjectives, step.ObjectiveIds)
+}
+
+// SpellLevelsOf returns the levels of a spell in the order of the spell. Missing levels are skipped.
+func (d *JSONGameData) SpellLevelsOf(spellId int) []JSONGameSpellLevel {
+	spell, found := d.Spell(spellId)
+	if !found {
+		return nil
+	}
+	return lookupAll(d.spellLevels, spell.SpellLevels)
+}
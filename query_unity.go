@@ -0,0 +1,300 @@
+package dodumap
+
+import "iter"
+
+// Item returns the item with id.
+func (d *JSONGameDataUnity) Item(id int) (JSONGameItemUnity, bool) {
+	return lookup(d.Items, id)
+}
+
+// AllItems iterates over all items sorted by id.
+func (d *JSONGameDataUnity) AllItems() iter.Seq2[int, JSONGameItemUnity] {
+	return sortedAll(d.Items)
+}
+
+// Set returns the item set with id.
+func (d *JSONGameDataUnity) Set(id int) (JSONGameSetUnity, bool) {
+	return lookup(d.Sets, id)
+}
+
+// AllSets iterates over all item sets sorted by id.
+func (d *JSONGameDataUnity) AllSets() iter.Seq2[int, JSONGameSetUnity] {
+	return sortedAll(d.Sets)
+}
+
+// ItemType returns the item type with id.
+func (d *JSONGameDataUnity) ItemType(id int) (JSONGameItemTypeUnity, bool) {
+	return lookup(d.ItemTypes, id)
+}
+
+// AllItemTypes iterates over all item types sorted by id.
+func (d *JSONGameDataUnity) AllItemTypes() iter.Seq2[int, JSONGameItemTypeUnity] {
+	return sortedAll(d.ItemTypes)
+}
+
+// Effect returns the effect with id.
+func (d *JSONGameDataUnity) Effect(id int) (JSONGameEffectUnity, bool) {
+	return lookup(d.effects, id)
+}
+
+// AllEffects iterates over all effects sorted by id.
+func (d *JSONGameDataUnity) AllEffects() iter.Seq2[int, JSONGameEffectUnity] {
+	return sortedAll(d.effects)
+}
+
+// Bonus returns the bonus with id.
+func (d *JSONGameDataUnity) Bonus(id int) (JSONGameBonusUnity, bool) {
+	return lookup(d.bonuses, id)
+}
+
+// AllBonuses iterates over all bonuses sorted by id.
+func (d *JSONGameDataUnity) AllBonuses() iter.Seq2[int, JSONGameBonusUnity] {
+	return sortedAll(d.bonuses)
+}
+
+// Recipe returns the recipe with id.
+func (d *JSONGameDataUnity) Recipe(id int) (JSONGameRecipeUnity, bool) {
+	return lookup(d.Recipes, id)
+}
+
+// AllRecipes iterates over all recipes sorted by id.
+func (d *JSONGameDataUnity) AllRecipes() iter.Seq2[int, JSONGameRecipeUnity] {
+	return sortedAll(d.Recipes)
+}
+
+// Spell returns the spell with id.
+func (d *JSONGameDataUnity) Spell(id int) (JSONGameSpellUnity, bool) {
+	return lookup(d.spells, id)
+}
+
+// AllSpells iterates over all spells sorted by id.
+func (d *JSONGameDataUnity) AllSpells() iter.Seq2[int, JSONGameSpellUnity] {
+	return sortedAll(d.spells)
+}
+
+// SpellType returns the spell type with id.
+func (d *JSONGameDataUnity) SpellType(id int) (JSONGameSpellType, bool) {
+	return lookup(d.spellTypes, id)
+}
+
+// AllSpellTypes iterates over all spell types sorted by id.
+func (d *JSONGameDataUnity) AllSpellTypes() iter.Seq2[int, JSONGameSpellType] {
+	return sortedAll(d.spellTypes)
+}
+
+// SpellLevel returns the spell level with id.
+func (d *JSONGameDataUnity) SpellLevel(id int) (JSONGameSpellLevelUnity, bool) {
+	return lookup(d.spellLevels, id)
+}
+
+// AllSpellLevels iterates over all spell levels sorted by id.
+func (d *JSONGameDataUnity) AllSpellLevels() iter.Seq2[int, JSONGameSpellLevelUnity] {
+	return sortedAll(d.spellLevels)
+}
+
+// Area returns the area with id.
+func (d *JSONGameDataUnity) Area(id int) (JSONGameAreaUnity, bool) {
+	return lookup(d.areas, id)
+}
+
+// AllAreas iterates over all areas sorted by id.
+func (d *JSONGameDataUnity) AllAreas() iter.Seq2[int, JSONGameAreaUnity] {
+	return sortedAll(d.areas)
+}
+
+// SuperArea returns the super area with id.
+func (d *JSONGameDataUnity) SuperArea(id int) (JSONGameSuperAreaUnity, bool) {
+	return lookup(d.superAreas, id)
+}
+
+// SubArea returns the sub-area with id.
+func (d *JSONGameDataUnity) SubArea(id int) (JSONGameSubArea, bool) {
+	return lookup(d.subAreas, id)
+}
+
+// AllSubAreas iterates over all sub-areas sorted by id.
+func (d *JSONGameDataUnity) AllSubAreas() iter.Seq2[int, JSONGameSubArea] {
+	return sortedAll(d.subAreas)
+}
+
+// Emote returns the emote with id.
+func (d *JSONGameDataUnity) Emote(id int) (JSONGameEmote, bool) {
+	return lookup(d.emotes, id)
+}
+
+// Achievement returns the achievement with id.
+func (d *JSONGameDataUnity) Achievement(id int) (JSONGameAchievement, bool) {
+	return lookup(d.achievements, id)
+}
+
+// Mount returns the mount with id.
+func (d *JSONGameDataUnity) Mount(id int) (JSONGameMountUnity, bool) {
+	return lookup(d.Mounts, id)
+}
+
+// AllMounts iterates over all mounts sorted by id.
+func (d *JSONGameDataUnity) AllMounts() iter.Seq2[int, JSONGameMountUnity] {
+	return sortedAll(d.Mounts)
+}
+
+// MountFamily returns the mount family with id.
+func (d *JSONGameDataUnity) MountFamily(id int) (JSONGameMountFamilyUnity, bool) {
+	return lookup(d.MountFamilys, id)
+}
+
+// AllMountFamilies iterates over all mount families sorted by id.
+func (d *JSONGameDataUnity) AllMountFamilies() iter.Seq2[int, JSONGameMountFamilyUnity] {
+	return sortedAll(d.MountFamilys)
+}
+
+// Breed returns the breed with id.
+func (d *JSONGameDataUnity) Breed(id int) (JSONGameBreedUnity, bool) {
+	return lookup(d.classes, id)
+}
+
+// AllBreeds iterates over all breeds sorted by id.
+func (d *JSONGameDataUnity) AllBreeds() iter.Seq2[int, JSONGameBreedUnity] {
+	return sortedAll(d.classes)
+}
+
+// NPC returns the npc with id.
+func (d *JSONGameDataUnity) NPC(id int) (JSONGameNPCUnity, bool) {
+	return lookup(d.npcs, id)
+}
+
+// AllNPCs iterates over all npcs sorted by id.
+func (d *JSONGameDataUnity) AllNPCs() iter.Seq2[int, JSONGameNPCUnity] {
+	return sortedAll(d.npcs)
+}
+
+// Monster returns the monster with id.
+func (d *JSONGameDataUnity) Monster(id int) (JSONGameMonsterUnity, bool) {
+	return lookup(d.monsters, id)
+}
+
+// AllMonsters iterates over all monsters sorted by id.
+func (d *JSONGameDataUnity) AllMonsters() iter.Seq2[int, JSONGameMonsterUnity] {
+	return sortedAll(d.monsters)
+}
+
+// MonsterRace returns the monster race with id.
+func (d *JSONGameDataUnity) MonsterRace(id int) (JSONGameMonsterRaceUnity, bool) {
+	return lookup(d.monsterRaces, id)
+}
+
+// Job returns the job with id.
+func (d *JSONGameDataUnity) Job(id int) (JSONGameJob, bool) {
+	return lookup(d.jobs, id)
+}
+
+// AllJobs iterates over all jobs sorted by id.
+func (d *JSONGameDataUnity) AllJobs() iter.Seq2[int, JSONGameJob] {
+	return sortedAll(d.jobs)
+}
+
+// Skill returns the skill with id.
+func (d *JSONGameDataUnity) Skill(id int) (JSONGameSkillUnity, bool) {
+	return lookup(d.skills, id)
+}
+
+// AllSkills iterates over all skills sorted by id.
+func (d *JSONGameDataUnity) AllSkills() iter.Seq2[int, JSONGameSkillUnity] {
+	return sortedAll(d.skills)
+}
+
+// Title returns the title with id.
+func (d *JSONGameDataUnity) Title(id int) (JSONGameTitleUnity, bool) {
+	return lookup(d.titles, id)
+}
+
+// AllTitles iterates over all titles sorted by id.
+func (d *JSONGameDataUnity) AllTitles() iter.Seq2[int, JSONGameTitleUnity] {
+	return sortedAll(d.titles)
+}
+
+// Quest returns the quest with id.
+func (d *JSONGameDataUnity) Quest(id int) (JSONGameQuestUnity, bool) {
+	return lookup(d.quests, id)
+}
+
+// AllQuests iterates over all quests sorted by id.
+func (d *JSONGameDataUnity) AllQuests() iter.Seq2[int, JSONGameQuestUnity] {
+	return sortedAll(d.quests)
+}
+
+// QuestStep returns the quest step with id.
+func (d *JSONGameDataUnity) QuestStep(id int) (JSONGameQuestStepUnity, bool) {
+	return lookup(d.questSteps, id)
+}
+
+// AllQuestSteps iterates over all quest steps sorted by id.
+func (d *JSONGameDataUnity) AllQuestSteps() iter.Seq2[int, JSONGameQuestStepUnity] {
+	return sortedAll(d.questSteps)
+}
+
+// QuestObjective returns the quest objective with id.
+func (d *JSONGameDataUnity) QuestObjective(id int) (JSONGameQuestObjectiveUnity, bool) {
+	return lookup(d.questObjectives, id)
+}
+
+// AllQuestObjectives iterates over all quest objectives sorted by id.
+func (d *JSONGameDataUnity) AllQuestObjectives() iter.Seq2[int, JSONGameQuestObjectiveUnity] {
+	return sortedAll(d.questObjectives)
+}
+
+// QuestStepRewards returns the quest step rewards with id.
+func (d *JSONGameDataUnity) QuestStepRewards(id int) (JSONGameQuestStepRewardsUnity, bool) {
+	return lookup(d.questStepRewards, id)
+}
+
+// AllQuestStepRewards iterates over all quest step rewards sorted by id.
+func (d *JSONGameDataUnity) AllQuestStepRewards() iter.Seq2[int, JSONGameQuestStepRewardsUnity] {
+	return sortedAll(d.questStepRewards)
+}
+
+// QuestCategory returns the quest category with id.
+func (d *JSONGameDataUnity) QuestCategory(id int) (JSONGameQuestCategoryUnity, bool) {
+	return lookup(d.questCategories, id)
+}
+
+// AllQuestCategories iterates over all quest categories sorted by id.
+func (d *JSONGameDataUnity) AllQuestCategories() iter.Seq2[int, JSONGameQuestCategoryUnity] {
+	return sortedAll(d.questCategories)
+}
+
+// AlmanaxCalendar returns the almanax calendar with id.
+func (d *JSONGameDataUnity) AlmanaxCalendar(id int) (JSONGameAlamanaxCalendarUnity, bool) {
+	return lookup(d.almanaxCalendars, id)
+}
+
+// AllAlmanaxCalendars iterates over all almanax calendars sorted by id.
+func (d *JSONGameDataUnity) AllAlmanaxCalendars() iter.Seq2[int, JSONGameAlamanaxCalendarUnity] {
+	return sortedAll(d.almanaxCalendars)
+}
+
+// QuestStepsOf returns the steps of a quest in their order. Missing steps are skipped.
+func (d *JSONGameDataUnity) QuestStepsOf(questId int) []JSONGameQuestStepUnity {
+	quest, found := d.Quest(questId)
+	if !found {
+		return nil
+	}
+	return lookupAll(d.questSteps, quest.StepIds.Array)
+}
+
+// QuestObjectivesOf returns the objectives of a quest step in their order. Missing objectives are skipped.
+func (d *JSONGameDataUnity) QuestObjectivesOf(stepId int) []JSONGameQuestObjectiveUnity {
+	step, found := d.QuestStep(stepId)
+	if !found {
+		return nil
+	}
+	return lookupAll(d.questObjectives, step.ObjectiveIds.Array)
+}
+
+// SpellLevelsOf returns the levels of a spell in the order of the spell. Missing levels are skipped.
+func (d *JSONGameDataUnity) SpellLevelsOf(spellId int) []JSONGameSpellLevelUnity {
+	spell, found := d.Spell(spellId)
+	if !found {
+		return nil
+	}
+	return lookupAll(d.spellLevels, spell.SpellLevels.Array)
+}
@@ -0,0 +1,307 @@
+package dodumap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QuestGraph connects quests with the quests that have to be completed before they can be started,
+// taken from the "Qf" criteria of their start criterion. Only quests that are required in every case,
+// like "Qf=1&(Qf=2|Qf=3)" requires 1, are prerequisites. Alternatives like 2 and 3 are kept apart, so
+// they neither show up in chains nor make the quests that point at each other over them a cycle.
+type QuestGraph struct {
+	quests        []int         // all quest ids, ascending
+	prerequisites map[int][]int // quest -> quests to complete first, ascending
+	unlocks       map[int][]int // quest -> quests it is a prerequisite of, ascending
+	alternatives  map[int][]int // quest -> quests of which completing some can be enough, ascending
+}
+
+func NewQuestGraph(data *JSONGameData) (*QuestGraph, error) {
+	if data == nil {
+		return nil, ErrNilInput
+	}
+
+	return newQuestGraph(NewGameData(data, nil))
+}
+
+// newQuestGraph builds the graph over all quests of data. References to quests that do not exist are
+// reported and left out.
+func newQuestGraph(data GameData) (*QuestGraph, error) {
+	graph := &QuestGraph{
+		prerequisites: make(map[int][]int),
+		unlocks:       make(map[int][]int),
+		alternatives:  make(map[int][]int),
+	}
+
+	var errs []error
+	for questId, quest := range data.AllQuests() {
+		graph.quests = append(graph.quests, questId)
+		criterion, err := parseExpression(quest.StartCriterion)
+		if err != nil {
+			errs = append(errs, newDataError("quests.json", questId, "startCriterion", err))
+		}
+		required := completedQuestCriteria(criterion, true)
+		for _, prerequisite := range completedQuestCriteria(criterion, false) {
+			if _, found := data.Quest(prerequisite); !found {
+				errs = append(errs, newDataError("quests.json", questId, "startCriterion", fmt.Errorf("quest %d: %w", prerequisite, ErrMissingReference)))
+				continue
+			}
+			switch {
+			case slices.Contains(required, prerequisite):
+				if !slices.Contains(graph.prerequisites[questId], prerequisite) {
+					graph.prerequisites[questId] = append(graph.prerequisites[questId], prerequisite)
+					graph.unlocks[prerequisite] = append(graph.unlocks[prerequisite], questId)
+				}
+			case !slices.Contains(graph.alternatives[questId], prerequisite):
+				graph.alternatives[questId] = append(graph.alternatives[questId], prerequisite)
+			}
+		}
+		slices.Sort(graph.prerequisites[questId])
+		slices.Sort(graph.alternatives[questId])
+	}
+	for _, unlocks := range graph.unlocks {
+		slices.Sort(unlocks)
+	}
+
+	return graph, errors.Join(errs...)
+}
+
+// completedQuestCriteria collects the quest ids of the "Qf=<id>" operands in the tree. With requiredOnly,
+// operands below an "|" are left out since the other side could be met instead.
+func completedQuestCriteria(node *ConditionTreeNode, requiredOnly bool) []int {
+	if node == nil || requiredOnly && node.Type == Operator && node.Value == "|" {
+		return nil
+	}
+	if node.Type == Operand {
+		questId, err := strconv.Atoi(strings.TrimPrefix(node.Value, "Qf="))
+		if err != nil || !strings.HasPrefix(node.Value, "Qf=") {
+			return nil
+		}
+		return []int{questId}
+	}
+	var questIds []int
+	for _, child := range node.Children {
+		questIds = append(questIds, completedQuestCriteria(child, requiredOnly)...)
+	}
+	return questIds
+}
+
+// Prerequisites returns the quests that have to be completed before questId can be started.
+func (g *QuestGraph) Prerequisites(questId int) []int {
+	return slices.Clone(g.prerequisites[questId])
+}
+
+// Alternatives returns the quests that can lead to questId, where completing one of them may be
+// enough. Other criteria decide which, so they are not part of Chain, Order and Cycles.
+func (g *QuestGraph) Alternatives(questId int) []int {
+	return slices.Clone(g.alternatives[questId])
+}
+
+// Unlocks returns the quests that need questId to be completed.
+func (g *QuestGraph) Unlocks(questId int) []int {
+	return slices.Clone(g.unlocks[questId])
+}
+
+// Chain returns all quests that lead to questId, directly or not, in an order they can be done in.
+// questId is the last one. Quests without dependency between each other are ordered by id.
+func (g *QuestGraph) Chain(questId int) ([]int, error) {
+	if _, found := slices.BinarySearch(g.quests, questId); !found {
+		return nil, fmt.Errorf("quest %d: %w", questId, ErrMissingReference)
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[int]int)
+	var chain []int
+	var visit func(id int, path []int) error
+	visit = func(id int, path []int) error {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			cycleStart := slices.Index(path, id)
+			return fmt.Errorf("%w: %v", ErrQuestCycle, append(path[cycleStart:], id))
+		}
+		state[id] = visiting
+		for _, prerequisite := range g.prerequisites[id] {
+			if err := visit(prerequisite, append(path, id)); err != nil {
+				return err
+			}
+		}
+		state[id] = done
+		chain = append(chain, id)
+		return nil
+	}
+
+	if err := visit(questId, nil); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// Order returns all quests so that every quest comes after its prerequisites, or ErrQuestCycle when
+// that is not possible. Quests that could be done at the same time are ordered by id.
+func (g *QuestGraph) Order() ([]int, error) {
+	missing := make(map[int]int, len(g.prerequisites))
+	for questId, prerequisites := range g.prerequisites {
+		missing[questId] = len(prerequisites)
+	}
+
+	var ready []int // ascending
+	for _, questId := range g.quests {
+		if missing[questId] == 0 {
+			ready = append(ready, questId)
+		}
+	}
+
+	order := make([]int, 0, len(g.quests))
+	for len(ready) > 0 {
+		questId := ready[0]
+		ready = ready[1:]
+		order = append(order, questId)
+		for _, unlocked := range g.unlocks[questId] {
+			missing[unlocked]--
+			if missing[unlocked] == 0 {
+				idx, _ := slices.BinarySearch(ready, unlocked)
+				ready = slices.Insert(ready, idx, unlocked)
+			}
+		}
+	}
+
+	if len(order) != len(g.quests) {
+		return order, fmt.Errorf("%w: %v", ErrQuestCycle, g.Cycles())
+	}
+	return order, nil
+}
+
+// Cycles returns the groups of quests that require each other, each ascending and ordered by their first quest.
+func (g *QuestGraph) Cycles() [][]int {
+	// tarjan's strongly connected components
+	index := make(map[int]int)
+	lowLink := make(map[int]int)
+	onStack := make(map[int]bool)
+	var stack []int
+	var cycles [][]int
+
+	var connect func(id int)
+	connect = func(id int) {
+		index[id] = len(index)
+		lowLink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, prerequisite := range g.prerequisites[id] {
+			if _, visited := index[prerequisite]; !visited {
+				connect(prerequisite)
+				lowLink[id] = Min(lowLink[id], lowLink[prerequisite])
+			} else if onStack[prerequisite] {
+				lowLink[id] = Min(lowLink[id], index[prerequisite])
+			}
+		}
+
+		if lowLink[id] != index[id] {
+			return
+		}
+		var component []int
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == id {
+				break
+			}
+		}
+		if len(component) > 1 || slices.Contains(g.prerequisites[id], id) {
+			slices.Sort(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, questId := range g.quests {
+		if _, visited := index[questId]; !visited {
+			connect(questId)
+		}
+	}
+
+	slices.SortFunc(cycles, func(a, b []int) int { return a[0] - b[0] })
+	return cycles
+}
+
+// connected returns the quests that have a prerequisite or alternative or lead to another quest, ascending.
+func (g *QuestGraph) connected() []int {
+	alternative := make(map[int]bool)
+	for _, alternatives := range g.alternatives {
+		for _, questId := range alternatives {
+			alternative[questId] = true
+		}
+	}
+	var questIds []int
+	for _, questId := range g.quests {
+		if len(g.prerequisites[questId]) > 0 || len(g.unlocks[questId]) > 0 || len(g.alternatives[questId]) > 0 || alternative[questId] {
+			questIds = append(questIds, questId)
+		}
+	}
+	return questIds
+}
+
+// WriteDOT writes the quests that are part of a chain as graphviz digraph with an edge from every
+// prerequisite to the quest it unlocks and a dashed edge from every alternative. label names the nodes,
+// the quest ids are used when it is nil.
+func (g *QuestGraph) WriteDOT(w io.Writer, label func(questId int) string) error {
+	var sb strings.Builder
+	sb.WriteString("digraph quests {\n")
+	for _, questId := range g.connected() {
+		name := strconv.Itoa(questId)
+		if label != nil {
+			name = label(questId)
+		}
+		fmt.Fprintf(&sb, "    q%d [label=%s];\n", questId, strconv.Quote(name))
+	}
+	for _, questId := range g.connected() {
+		for _, unlocked := range g.unlocks[questId] {
+			fmt.Fprintf(&sb, "    q%d -> q%d;\n", questId, unlocked)
+		}
+	}
+	for _, questId := range g.connected() {
+		for _, alternative := range g.alternatives[questId] {
+			fmt.Fprintf(&sb, "    q%d -> q%d [style=dashed];\n", alternative, questId)
+		}
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMermaid writes the same graph as WriteDOT as mermaid flowchart.
+func (g *QuestGraph) WriteMermaid(w io.Writer, label func(questId int) string) error {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	for _, questId := range g.connected() {
+		name := strconv.Itoa(questId)
+		if label != nil {
+			name = label(questId)
+		}
+		fmt.Fprintf(&sb, "    q%d[\"%s\"]\n", questId, strings.ReplaceAll(name, "\"", "#quot;"))
+	}
+	for _, questId := range g.connected() {
+		for _, unlocked := range g.unlocks[questId] {
+			fmt.Fprintf(&sb, "    q%d --> q%d\n", questId, unlocked)
+		}
+	}
+	for _, questId := range g.connected() {
+		for _, alternative := range g.alternatives[questId] {
+			fmt.Fprintf(&sb, "    q%d -.-> q%d\n", alternative, questId)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,9 @@
+package dodumap
+
+func NewQuestGraphUnity(data *JSONGameDataUnity) (*QuestGraph, error) {
+	if data == nil {
+		return nil, ErrNilInput
+	}
+
+	return newQuestGraph(NewGameDataUnity(data, nil))
+}
@@ -0,0 +1,212 @@
+package dodumap
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RecipeBook looks up recipes across several crafting levels, like what has to be farmed for an item.
+type RecipeBook struct {
+	recipes map[int]MappedMultilangRecipe // by result item id
+	usedIn  map[int][]int                 // ingredient -> results of the recipes it is part of, ascending
+}
+
+// NewRecipeBook indexes the recipes from MapRecipes or MapRecipesUnity.
+func NewRecipeBook(recipes []MappedMultilangRecipe) *RecipeBook {
+	book := &RecipeBook{
+		recipes: make(map[int]MappedMultilangRecipe, len(recipes)),
+		usedIn:  make(map[int][]int),
+	}
+	for _, recipe := range recipes {
+		book.recipes[recipe.ResultId] = recipe
+		for _, entry := range recipe.Entries {
+			if !slices.Contains(book.usedIn[entry.ItemId], recipe.ResultId) {
+				book.usedIn[entry.ItemId] = append(book.usedIn[entry.ItemId], recipe.ResultId)
+			}
+		}
+	}
+	for _, results := range book.usedIn {
+		slices.Sort(results)
+	}
+	return book
+}
+
+// Recipe returns the recipe that crafts itemId.
+func (b *RecipeBook) Recipe(itemId int) (MappedMultilangRecipe, bool) {
+	recipe, found := b.recipes[itemId]
+	return recipe, found
+}
+
+// RecipeNode is an item in a crafting tree. Items without recipe are resources and have no ingredients.
+type RecipeNode struct {
+	ItemId      int           `json:"item_id"`
+	Quantity    int           `json:"quantity"` // for all crafts of the root item
+	Craftable   bool          `json:"craftable"`
+	JobId       int           `json:"job_id"` // only set when craftable
+	Level       int           `json:"level"`  // only set when craftable
+	Ingredients []*RecipeNode `json:"ingredients"`
+}
+
+// RecipeJob is a job with the highest level that is needed in a crafting tree.
+type RecipeJob struct {
+	JobId int `json:"job_id"`
+	Level int `json:"level"`
+}
+
+type RecipeExpansion struct {
+	Tree      *RecipeNode                  `json:"tree"`
+	Resources []MappedMultilangRecipeEntry `json:"resources"` // total of every resource, ordered by item id
+	Jobs      []RecipeJob                  `json:"jobs"`      // ordered by job id
+}
+
+// Expand crafts itemId crafts times down to the items that have no recipe. It fails with ErrRecipeCycle
+// when an item is needed to craft itself.
+func (b *RecipeBook) Expand(itemId int, crafts int) (RecipeExpansion, error) {
+	var expansion RecipeExpansion
+	tree, err := b.expand(itemId, crafts, nil)
+	if err != nil {
+		return expansion, err
+	}
+	expansion.Tree = tree
+
+	resources := make(map[int]int)
+	jobs := make(map[int]int)
+	var collect func(node *RecipeNode)
+	collect = func(node *RecipeNode) {
+		if !node.Craftable {
+			resources[node.ItemId] += node.Quantity
+			return
+		}
+		jobs[node.JobId] = Max(jobs[node.JobId], node.Level)
+		for _, ingredient := range node.Ingredients {
+			collect(ingredient)
+		}
+	}
+	collect(tree)
+
+	for resourceId, quantity := range resources {
+		expansion.Resources = append(expansion.Resources, MappedMultilangRecipeEntry{ItemId: resourceId, Quantity: quantity})
+	}
+	slices.SortFunc(expansion.Resources, func(a, b MappedMultilangRecipeEntry) int { return a.ItemId - b.ItemId })
+	for jobId, level := range jobs {
+		expansion.Jobs = append(expansion.Jobs, RecipeJob{JobId: jobId, Level: level})
+	}
+	slices.SortFunc(expansion.Jobs, func(a, b RecipeJob) int { return a.JobId - b.JobId })
+
+	return expansion, nil
+}
+
+// expand builds the tree of itemId. path holds the items that are crafted above it.
+func (b *RecipeBook) expand(itemId int, quantity int, path []int) (*RecipeNode, error) {
+	if slices.Contains(path, itemId) {
+		cycle := append(path[slices.Index(path, itemId):], itemId)
+		return nil, fmt.Errorf("%w: %v", ErrRecipeCycle, cycle)
+	}
+
+	node := &RecipeNode{ItemId: itemId, Quantity: quantity}
+	recipe, found := b.recipes[itemId]
+	if !found {
+		return node, nil
+	}
+	node.Craftable = true
+	node.JobId = recipe.JobId
+	node.Level = recipe.Level
+
+	path = append(path, itemId)
+	for _, entry := range recipe.Entries {
+		ingredient, err := b.expand(entry.ItemId, entry.Quantity*quantity, path)
+		if err != nil {
+			return nil, err
+		}
+		node.Ingredients = append(node.Ingredients, ingredient)
+	}
+	return node, nil
+}
+
+// RecipeUsage is an item that needs a resource somewhere in its crafting tree.
+type RecipeUsage struct {
+	ItemId   int `json:"item_id"`
+	JobId    int `json:"job_id"`
+	Level    int `json:"level"`
+	Depth    int `json:"depth"`    // 1 when the resource is a direct ingredient, the shortest way otherwise
+	Quantity int `json:"quantity"` // of the resource for one craft, over all ways it is used
+}
+
+// UsedIn returns every item that needs itemId to be crafted, directly or through other crafted items,
+// ordered by depth and item id. With jobIds, only items crafted by these jobs are returned, but the
+// search still goes through the others. It fails with ErrRecipeCycle when items that need itemId also
+// need each other.
+func (b *RecipeBook) UsedIn(itemId int, jobIds ...int) ([]RecipeUsage, error) {
+	// breadth first for the depths
+	depths := map[int]int{itemId: 0}
+	queue := []int{itemId}
+	var reached []int
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, result := range b.usedIn[current] {
+			if _, seen := depths[result]; seen {
+				continue
+			}
+			depths[result] = depths[current] + 1
+			queue = append(queue, result)
+			reached = append(reached, result)
+		}
+	}
+
+	// quantity of itemId in one craft of every reached item
+	quantities := map[int]int{itemId: 1}
+	inProgress := make(map[int]bool)
+	var quantity func(id int, path []int) (int, error)
+	quantity = func(id int, path []int) (int, error) {
+		if amount, done := quantities[id]; done {
+			return amount, nil
+		}
+		if _, needsItem := depths[id]; !needsItem {
+			return 0, nil
+		}
+		if inProgress[id] {
+			cycle := append(path[slices.Index(path, id):], id)
+			return 0, fmt.Errorf("%w: %v", ErrRecipeCycle, cycle)
+		}
+		inProgress[id] = true
+		total := 0
+		for _, entry := range b.recipes[id].Entries {
+			amount, err := quantity(entry.ItemId, append(path, id))
+			if err != nil {
+				return 0, err
+			}
+			total += entry.Quantity * amount
+		}
+		inProgress[id] = false
+		quantities[id] = total
+		return total, nil
+	}
+
+	var usages []RecipeUsage
+	for _, result := range reached {
+		recipe := b.recipes[result]
+		amount, err := quantity(result, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(jobIds) > 0 && !slices.Contains(jobIds, recipe.JobId) {
+			continue
+		}
+		usages = append(usages, RecipeUsage{
+			ItemId:   result,
+			JobId:    recipe.JobId,
+			Level:    recipe.Level,
+			Depth:    depths[result],
+			Quantity: amount,
+		})
+	}
+
+	slices.SortFunc(usages, func(a, b RecipeUsage) int {
+		if a.Depth != b.Depth {
+			return a.Depth - b.Depth
+		}
+		return a.ItemId - b.ItemId
+	})
+	return usages, nil
+}
@@ -0,0 +1,326 @@
+package dodumap
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/emirpasic/gods/maps/treebidimap"
+	gutils "github.com/emirpasic/gods/utils"
+)
+
+// renameSimilarity is the minimum similarity between a new and a known name to report a possible rename.
+const renameSimilarity = 0.85
+
+// IDRegistry assigns stable ids to english names, so element and item type ids do not change between
+// game versions. Names are matched exactly, then through declared aliases and then ignoring case and
+// whitespace. It is safe for concurrent use.
+type IDRegistry struct {
+	mu         sync.RWMutex
+	entries    *treebidimap.Map
+	nextId     int
+	normalized map[string]int    // normalized name -> id of the first name with that form
+	aliases    map[string]string // normalized alias -> name
+	renames    []PossibleRename
+	meta       map[int]*RegistryEntry
+	version    string // game version of the current run, empty when no run was started
+}
+
+// RegistryEntry is the persisted form of a single id. The id is the index in the saved array.
+// Entries loaded from the old plain string arrays have no versions and occurrences.
+type RegistryEntry struct {
+	Name        string `json:"name"`
+	FirstSeen   string `json:"first_seen,omitempty"`  // game version that produced the name first
+	LastSeen    string `json:"last_seen,omitempty"`   // last game version that produced the name
+	Occurrences int    `json:"occurrences,omitempty"` // how often the name was produced in the last run
+}
+
+// UnmarshalJSON also accepts a plain string, the format before versions were tracked.
+func (e *RegistryEntry) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*e = RegistryEntry{}
+		return json.Unmarshal(data, &e.Name)
+	}
+	type plainEntry RegistryEntry
+	return json.Unmarshal(data, (*plainEntry)(e))
+}
+
+// PossibleRename is reported when a new name got an id but is very similar to a name that is already known.
+// It usually means that Ankama changed the wording and an alias should be declared.
+type PossibleRename struct {
+	Name       string  `json:"name"`
+	Id         int     `json:"id"`
+	Similar    string  `json:"similar"`
+	SimilarId  int     `json:"similar_id"`
+	Similarity float64 `json:"similarity"`
+}
+
+func NewIDRegistry() *IDRegistry {
+	return &IDRegistry{
+		entries:    treebidimap.NewWith(gutils.IntComparator, gutils.StringComparator),
+		normalized: make(map[string]int),
+		aliases:    make(map[string]string),
+		meta:       make(map[int]*RegistryEntry),
+	}
+}
+
+// newIDRegistryFrom creates a registry where every name gets its index as id.
+func newIDRegistryFrom(names []string) *IDRegistry {
+	entries := make([]RegistryEntry, len(names))
+	for i, name := range names {
+		entries[i].Name = name
+	}
+	return newIDRegistryFromEntries(entries)
+}
+
+// newIDRegistryFromEntries creates a registry where every entry gets its index as id.
+func newIDRegistryFromEntries(entries []RegistryEntry) *IDRegistry {
+	registry := NewIDRegistry()
+	for _, entry := range entries {
+		registry.put(registry.nextId, entry.Name)
+		meta := entry
+		registry.meta[registry.nextId] = &meta
+		registry.nextId++
+	}
+	return registry
+}
+
+// normalizeRegistryName lowercases name and collapses all whitespace.
+func normalizeRegistryName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// put adds name without locking. The caller must hold the write lock or own the registry.
+func (r *IDRegistry) put(id int, name string) {
+	r.entries.Put(id, name)
+	normalized := normalizeRegistryName(name)
+	if _, found := r.normalized[normalized]; !found {
+		r.normalized[normalized] = id
+	}
+}
+
+// AddAlias declares alias as another wording of name, so both resolve to the id of name.
+func (r *IDRegistry) AddAlias(alias string, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[normalizeRegistryName(alias)] = name
+}
+
+// lookup resolves name without locking.
+func (r *IDRegistry) lookup(name string) (int, bool) {
+	if key, found := r.entries.GetKey(name); found {
+		return key.(int), true
+	}
+	normalized := normalizeRegistryName(name)
+	if aliased, found := r.aliases[normalized]; found {
+		if key, found := r.entries.GetKey(aliased); found {
+			return key.(int), true
+		}
+		normalized = normalizeRegistryName(aliased)
+	}
+	id, found := r.normalized[normalized]
+	return id, found
+}
+
+// Lookup returns the id of name if it is known.
+func (r *IDRegistry) Lookup(name string) (int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, found := r.lookup(name)
+	if !found {
+		return -1, false
+	}
+	return id, true
+}
+
+// BeginRun starts a mapping of gameVersion. From now on GetOrAssign counts the occurrences of every id
+// and marks it as seen in gameVersion. The counts of the previous run are reset.
+func (r *IDRegistry) BeginRun(gameVersion string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.version = gameVersion
+	for _, meta := range r.meta {
+		meta.Occurrences = 0
+	}
+}
+
+// seen updates the metadata of id for the current run. The caller must hold the write lock.
+func (r *IDRegistry) seen(id int) {
+	if r.version == "" {
+		return
+	}
+	meta := r.meta[id]
+	if meta.FirstSeen == "" {
+		meta.FirstSeen = r.version
+	}
+	meta.LastSeen = r.version
+	meta.Occurrences++
+}
+
+// GetOrAssign returns the id of name and assigns the next free id when name is new.
+// New names that are close to a known name are recorded, see PossibleRenames.
+func (r *IDRegistry) GetOrAssign(name string) (id int, created bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, found := r.lookup(name); found {
+		r.seen(id)
+		return id, false
+	}
+
+	rename, similar := r.mostSimilar(name)
+
+	id = r.nextId
+	r.put(id, name)
+	r.meta[id] = &RegistryEntry{Name: name}
+	r.nextId++
+	r.seen(id)
+
+	if similar {
+		rename.Id = id
+		r.renames = append(r.renames, rename)
+		log.Warn("possible rename", "name", name, "id", id, "similar", rename.Similar, "similar_id", rename.SimilarId)
+	}
+	return id, true
+}
+
+// mostSimilar finds the known name that is closest to name, if it is close enough to be a rename.
+func (r *IDRegistry) mostSimilar(name string) (PossibleRename, bool) {
+	normalized := normalizeRegistryName(name)
+	length := len([]rune(normalized))
+	best := PossibleRename{Name: name}
+	it := r.entries.Iterator()
+	for it.Next() {
+		known := it.Value().(string)
+		knownNormalized := normalizeRegistryName(known)
+		knownLength := len([]rune(knownNormalized))
+		if float64(Max(length, knownLength)-Min(length, knownLength)) > (1-renameSimilarity)*float64(Max(length, knownLength)) {
+			continue // the length difference alone is too big
+		}
+		similarity := stringSimilarity(normalized, knownNormalized)
+		if similarity > best.Similarity {
+			best.Similar = known
+			best.SimilarId = it.Key().(int)
+			best.Similarity = similarity
+		}
+	}
+	return best, best.Similarity >= renameSimilarity
+}
+
+// stringSimilarity is 1 minus the levenshtein distance relative to the longer string.
+func stringSimilarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := Max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = Min(Min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// PossibleRenames returns the new names since the registry was loaded that look like a rename of a known name.
+func (r *IDRegistry) PossibleRenames() []PossibleRename {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	renames := make([]PossibleRename, len(r.renames))
+	copy(renames, r.renames)
+	return renames
+}
+
+// Name returns the name that was assigned to id.
+func (r *IDRegistry) Name(id int) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	value, found := r.entries.Get(id)
+	if !found {
+		return "", false
+	}
+	return value.(string), true
+}
+
+// Entry returns the name of id together with its version metadata.
+func (r *IDRegistry) Entry(id int) (RegistryEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, found := r.meta[id]
+	if !found {
+		return RegistryEntry{}, false
+	}
+	return *meta, true
+}
+
+// Len returns the number of assigned ids.
+func (r *IDRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.nextId
+}
+
+// Save writes the entries as json array where the index is the id.
+func (r *IDRegistry) Save(w io.Writer) error {
+	r.mu.RLock()
+	entries := make([]RegistryEntry, r.nextId)
+	for id, meta := range r.meta {
+		entries[id] = *meta
+	}
+	r.mu.RUnlock()
+
+	entriesJson, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(entriesJson)
+	return err
+}
+
+// Load replaces the entries of the registry with a json array written by Save or with a plain array
+// of names like the files in doduda/persistent. Declared aliases and the current run are kept.
+func (r *IDRegistry) Load(rd io.Reader) error {
+	var entries []RegistryEntry
+	err := json.NewDecoder(rd).Decode(&entries)
+	if err != nil {
+		return err
+	}
+	loaded := newIDRegistryFromEntries(entries)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = loaded.entries
+	r.nextId = loaded.nextId
+	r.normalized = loaded.normalized
+	r.meta = loaded.meta
+	r.renames = nil
+	return nil
+}
+
+// PersistedIds holds the registries for effect and condition elements and for item types.
+type PersistedIds struct {
+	Elements *IDRegistry
+	Types    *IDRegistry
+}
+
+// BeginRun starts tracking gameVersion in both registries, see IDRegistry.BeginRun.
+func (p *PersistedIds) BeginRun(gameVersion string) {
+	p.Elements.BeginRun(gameVersion)
+	p.Types.BeginRun(gameVersion)
+}
+
+func (p *PersistedIds) loaded() bool {
+	return p != nil && p.Elements != nil && p.Types != nil
+}
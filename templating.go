@@ -2,11 +2,12 @@ package dodumap
 
 import (
 	"fmt"
-	"log"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/charmbracelet/log"
 )
 
 func Min(a, b int) int {
@@ -124,17 +125,23 @@ func ElementFromCode(codeUndef string) int {
 	case "cp":
 		return 501948 // "Action Points (AP)"
 	case "po":
+		if codeUndef == "PO" {
+			return -1 // item in inventory, no game text
+		}
 		return 1092470 // "Different area to: {0}"
 	case "pf":
 		return 1095105 // "{0} not equipped"
-	//case "": // Ps=1
-	//	return 644230 // Ausgerüstetes %1-Reittier
 	case "pa":
 		return 1093891 // "Alignment level"
-	//case "":
-	//	return 637203 // Kein ausgerüstetes %1-Reittier haben
 	case "of":
 		return 1094822 // "Have a {0} mount equipped"
+	case "ps":
+		if codeUndef == "Ps" {
+			return 644230 // "Ausgerüstetes %1-Reittier"
+		}
+		return -1
+	case "ps!": // "Ps=0" and "Ps!1", see criterion.negatedCode
+		return 637203 // "Kein ausgerüstetes %1-Reittier haben"
 	case "pz":
 		return 1093970 // "Be subscribed"
 	}
@@ -142,62 +149,92 @@ func ElementFromCode(codeUndef string) int {
 	return -1
 }
 
-func ConditionWithOperator(input string, operator string, langs *map[string]LangDict, out *MappedMultilangCondition, data *JSONGameData) bool {
+func ConditionWithOperator(input string, operator string, langs *map[string]LangDict, out *MappedMultilangCondition, data *JSONGameData, elements *IDRegistry) (bool, error) {
+	return conditionWithOperator(input, operator, NewGameData(data, *langs), out, elements)
+}
+
+// conditionWithOperator maps a single condition like "CS>25" that uses operator. It returns false for
+// conditions that are unknown or should not be shown.
+func conditionWithOperator(input string, operator string, data GameData, out *MappedMultilangCondition, elements *IDRegistry) (bool, error) {
 	partSplit := strings.Split(input, operator)
-	rawElement := ElementFromCode(partSplit[0])
-	if rawElement == -1 {
-		return false
+	known, found := lookupCriterion(partSplit[0])
+	if !found {
+		return false, nil
 	}
-	out.Element = partSplit[0]
-	out.Value, _ = strconv.Atoi(partSplit[1])
-	for _, lang := range Languages {
-		langStr := (*langs)[lang].Texts[rawElement]
+	out.Kind = known.kind
+	out.ValueKinds = known.values
 
-		if lang == "en" {
-			if langStr == "()" {
-				return false
-			}
-
-			keySanitized := DeleteReplacer(langStr)
-
-			if PersistedElements.Entries == nil {
-				log.Fatal("Elements Entries is nil")
-			}
-
-			key, foundKey := PersistedElements.Entries.GetKey(keySanitized)
-			if foundKey {
-				out.ElementId = key.(int)
-			} else {
-				PersistedElements.Entries.Put(PersistedElements.NextId, keySanitized)
-				PersistedElements.NextId++
+	out.Value, _ = strconv.Atoi(strings.Split(partSplit[1], ",")[0])
+	code := partSplit[0]
+	if known.negatedCode != "" && (operator == "=") == (out.Value == 0) {
+		code = known.negatedCode
+	}
+	rawElement := data.dialect().elementFromCode(code)
+	if rawElement == nil {
+		return subjectConditionWithOperator(partSplit[0], partSplit[1], operator, known, data, out)
+	}
+	out.Element = partSplit[0]
+	// newer versions moved some texts, so the first id that has a translation wins
+	conditionText := func(lang string) (string, bool) {
+		for _, textId := range rawElement {
+			if text, found := data.LookupText(lang, textId); found {
+				return text, true
 			}
 		}
+		return "", false
+	}
+	enText, found := conditionText("en")
+	if !found {
+		log.Warn("missing condition text", "code", partSplit[0], "text_ids", rawElement)
+		return false, nil // nothing to name it by in the element registry
+	}
+	if enText == "()" {
+		return false, nil
+	}
+	if elements == nil {
+		return false, ErrRegistryNotLoaded
+	}
+	out.ElementId, _ = elements.GetOrAssign(DeleteReplacer(enText))
 
-		switch rawElement {
-		case 837224: // %1 replace
-			intVal, _ := strconv.Atoi(partSplit[1])
-			langStr = strings.ReplaceAll(langStr, "%1", fmt.Sprint(intVal+1))
-		case 335357: // anderes gebiet als %1
-			langStr = strings.ReplaceAll(langStr, "%1", (*langs)[lang].Texts[data.areas[out.Value].NameId])
-		case 637212: // reittier %1
-		case 644231:
-			langStr = strings.ReplaceAll(langStr, "%1", (*langs)[lang].Texts[data.Mounts[out.Value].NameId])
+	for _, lang := range data.Languages() {
+		langStr, found := conditionText(lang)
+		if !found {
+			log.Warn("missing condition translation, using english", "code", partSplit[0], "lang", lang)
+			langStr = enText
 		}
 
-		out.Templated[lang] = langStr
+		out.Templated[lang] = known.templated(langStr, data, lang, []int{out.Value}, operator)
+		if known.subject != nil {
+			if subject := known.subject(data, lang, out.Value); subject != "" {
+				if out.Subject == nil {
+					out.Subject = make(map[string]string)
+				}
+				out.Subject[lang] = subject
+			}
+		}
 	}
 	out.Operator = operator
 
 	buggyConditions := []int{181}
 
-	return !slices.Contains(buggyConditions, out.ElementId)
+	return !slices.Contains(buggyConditions, out.ElementId), nil
 }
 
 // NumSpellFormatter returns info about min max with in. -1 "only_min", -2 "no_min_max"
 func NumSpellFormatter(input string, lang string, gameData *JSONGameData, langs *map[string]LangDict, diceNum *int, diceSide *int, value *int, effectNameId int, numIsSpell bool, useDice bool, frNumSigned *int, frSideSigned *int) (string, int) {
-	diceNumIsSpellId := *diceNum > 12000 || numIsSpell
-	diceSideIsSpellId := *diceSide > 12000
-	valueIsSpellId := *value > 12000
+	return numSpellFormatter(input, lang, NewGameData(gameData, *langs), diceNum, diceSide, value, effectNameId, numIsSpell, useDice, frNumSigned, frSideSigned)
+}
+
+func numSpellFormatter(input string, lang string, data GameData, diceNum *int, diceSide *int, value *int, effectNameId int, numIsSpell bool, useDice bool, frNumSigned *int, frSideSigned *int) (string, int) {
+	dialect := data.dialect()
+	spellName := func(spellId int) string {
+		spell, _ := data.Spell(spellId)
+		return data.Text(lang, spell.NameId)
+	}
+
+	diceNumIsSpellId := *diceNum > dialect.spellIdThreshold || numIsSpell
+	diceSideIsSpellId := *diceSide > dialect.spellIdThreshold
+	valueIsSpellId := *value > dialect.spellIdThreshold
 
 	onlyNoMinMax := 0
 
@@ -209,17 +246,19 @@ func NumSpellFormatter(input string, lang string, gameData *JSONGameData, langs
 
 	delValue := false
 
-	input, concatRegex := PrepareAndCreateRangeRegex(input, true)
+	input, concatRegex := dialect.prepareRangeRegex(input, true)
 	var numSigned bool
 	var sideSigned bool
 	var ptSideSigned bool
-	_, ptSideSigned = ParseSigness(input)
+	_, ptSideSigned = dialect.parseSigness(input)
 	if *frNumSigned != 2 || *frSideSigned != 2 { // 2 is unset, 0 is false, 1 is true
 		numSigned = *frNumSigned == 1
 		sideSigned = *frSideSigned == 1
 	} else {
+		// french decides for all languages, but when its template was not formatted (like a special spell)
+		// every language uses its own signedness
+		numSigned, sideSigned = dialect.parseSigness(input)
 		if lang == "fr" {
-			numSigned, sideSigned = ParseSigness(input)
 			if numSigned {
 				*frNumSigned = 1
 			} else {
@@ -230,8 +269,6 @@ func NumSpellFormatter(input string, lang string, gameData *JSONGameData, langs
 			} else {
 				*frSideSigned = 0
 			}
-		} else {
-			log.Fatalf("frNumSigned and frSideSigned must be set for %s", lang)
 		}
 	}
 	concatEntries := concatRegex.FindAllStringSubmatch(input, -1)
@@ -251,7 +288,7 @@ func NumSpellFormatter(input string, lang string, gameData *JSONGameData, langs
 	for _, extracted := range num1Entries {
 		var diceNumStr string
 		if diceNumIsSpellId {
-			diceNumStr = (*langs)[lang].Texts[gameData.spells[*diceNum].NameId]
+			diceNumStr = spellName(*diceNum)
 		} else {
 			diceNumStr = fmt.Sprint(*diceNum)
 		}
@@ -263,7 +300,7 @@ func NumSpellFormatter(input string, lang string, gameData *JSONGameData, langs
 	} else {
 		var diceSideStr string
 		if diceSideIsSpellId {
-			diceSideStr = (*langs)[lang].Texts[gameData.spells[*diceSide].NameId]
+			diceSideStr = spellName(*diceSide)
 			//del_dice_side = true
 		} else {
 			if sideSigned && lang == "pt" && !ptSideSigned {
@@ -277,7 +314,7 @@ func NumSpellFormatter(input string, lang string, gameData *JSONGameData, langs
 
 	var valueStr string
 	if valueIsSpellId {
-		valueStr = (*langs)[lang].Texts[gameData.spells[*value].NameId]
+		valueStr = spellName(*value)
 		delValue = true
 	} else {
 		valueStr = fmt.Sprint(*value)
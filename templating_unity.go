@@ -3,11 +3,7 @@ package dodumap
 import (
 	"fmt"
 	"regexp"
-	"slices"
-	"strconv"
 	"strings"
-
-	"github.com/charmbracelet/log"
 )
 
 func DeleteDamageFormatterUnity(input string) string {
@@ -88,7 +84,7 @@ func PrepareAndCreateRangeRegexUnity(input string, extract bool) (string, *regex
 	return PrepareTextForRegex(input), concatRegex
 }
 
-// NOTE: When changing here, also needs changes in ConditionWithOperatorUnity lower function body because some special cases of replacments
+// NOTE: When changing here, also needs changes in conditionWithOperator because some special cases of replacments
 func ElementFromCodeUnity(codeUndef string) []int {
 	code := strings.ToLower(codeUndef)
 
@@ -118,17 +114,23 @@ func ElementFromCodeUnity(codeUndef string) []int {
 	case "cp":
 		return []int{1143880, 864704} // "Action Points"
 	case "po":
+		if codeUndef == "PO" {
+			return nil // item in inventory, no game text
+		}
 		return []int{1092470} // "Different area to: {0}"
 	case "pf":
 		return []int{1095105} // "{0} not equipped"
-	//case "": // Ps=1
-	//	return 644230 // Ausgerüstetes %1-Reittier
 	case "pa":
 		return []int{1093891} // "Alignment level"
-	//case "":
-	//	return 637203 // Kein ausgerüstetes %1-Reittier haben
 	case "of":
 		return []int{1094822} // "Have a {0} mount equipped"
+	case "ps":
+		if codeUndef == "Ps" {
+			return []int{644230} // "Ausgerüstetes %1-Reittier"
+		}
+		return nil
+	case "ps!": // "Ps=0" and "Ps!1", see criterion.negatedCode
+		return []int{637203} // "Kein ausgerüstetes %1-Reittier haben"
 	case "pz":
 		return []int{1093970} // "Be subscribed"
 	}
@@ -136,212 +138,12 @@ func ElementFromCodeUnity(codeUndef string) []int {
 	return nil
 }
 
-func ConditionWithOperatorUnity(input string, operator string, langs *map[string]LangDictUnity, out *MappedMultilangCondition, data *JSONGameDataUnity) bool {
-	partSplit := strings.Split(input, operator)
-	rawElement := ElementFromCodeUnity(partSplit[0])
-	if rawElement == nil {
-		return false
-	}
-	actualElement := -1
-	out.Element = partSplit[0]
-	out.Value, _ = strconv.Atoi(partSplit[1])
-	for _, lang := range LanguagesUnity {
-		langStr, ok := (*langs)[lang].Texts[rawElement[0]]
-		// TODO remove this mess when 3.2 is out for some time, else ambiguity. And we have hardcoded size 2 here, which is also not really stable.
-		if !ok {
-			langStr, ok = (*langs)[lang].Texts[rawElement[1]]
-			if !ok {
-				log.Fatalf("Could not find condition translation for %s", partSplit[1])
-			} else {
-				actualElement = rawElement[1]
-			}
-		} else {
-			actualElement = rawElement[0]
-		}
-
-		if lang == "en" {
-			if langStr == "()" {
-				return false
-			}
-
-			keySanitized := DeleteReplacer(langStr)
-
-			if PersistedElements.Entries == nil {
-				log.Fatal("Elements Entries is nil")
-			}
-
-			key, foundKey := PersistedElements.Entries.GetKey(keySanitized)
-			if foundKey {
-				out.ElementId = key.(int)
-			} else {
-				PersistedElements.Entries.Put(PersistedElements.NextId, keySanitized)
-				PersistedElements.NextId++
-			}
-		}
-
-		switch actualElement {
-		case 1096588: // %1 replace
-			intVal, _ := strconv.Atoi(partSplit[1])
-			langStr = strings.ReplaceAll(langStr, "%1", fmt.Sprint(intVal+1))
-		case 1092470: // anderes gebiet als %1
-			langStr = strings.ReplaceAll(langStr, "%1", (*langs)[lang].Texts[data.areas[out.Value].NameId])
-		case 1094822: // reittier %1
-		case 1095105:
-			langStr = strings.ReplaceAll(langStr, "%1", (*langs)[lang].Texts[data.Mounts[out.Value].NameId])
-		}
-
-		out.Templated[lang] = langStr
-	}
-	out.Operator = operator
-
-	buggyConditions := []int{181}
-
-	return !slices.Contains(buggyConditions, out.ElementId)
+func ConditionWithOperatorUnity(input string, operator string, langs *map[string]LangDictUnity, out *MappedMultilangCondition, data *JSONGameDataUnity, elements *IDRegistry) (bool, error) {
+	return conditionWithOperator(input, operator, NewGameDataUnity(data, *langs), out, elements)
 }
 
 func NumSpellFormatterUnity(input string, lang string, gameData *JSONGameDataUnity, langs *map[string]LangDictUnity, diceNum *int, diceSide *int, value *int, effectNameId int, numIsSpell bool, useDice bool, frNumSigned *int, frSideSigned *int) (string, int) {
-	diceNumIsSpellId := *diceNum > 8000 || numIsSpell
-	diceSideIsSpellId := *diceSide > 8000
-	valueIsSpellId := *value > 8000
-
-	onlyNoMinMax := 0
-
-	// when + xp
-	if !useDice && *diceNum == 0 && *value == 0 && *diceSide != 0 {
-		*value = *diceSide
-		*diceSide = 0
-	}
-
-	delValue := false
-
-	input, concatRegex := PrepareAndCreateRangeRegexUnity(input, true)
-	var numSigned bool
-	var sideSigned bool
-	var ptSideSigned bool
-	_, ptSideSigned = ParseSignessUnity(input)
-	if *frNumSigned != 2 || *frSideSigned != 2 { // 2 is unset, 0 is false, 1 is true
-		numSigned = *frNumSigned == 1
-		sideSigned = *frSideSigned == 1
-	} else {
-		if lang == "fr" {
-			numSigned, sideSigned = ParseSignessUnity(input)
-			if numSigned {
-				*frNumSigned = 1
-			} else {
-				*frNumSigned = 0
-			}
-			if sideSigned {
-				*frSideSigned = 1
-			} else {
-				*frSideSigned = 0
-			}
-		} else {
-			log.Fatalf("frNumSigned and frSideSigned must be set for %s", lang)
-		}
-	}
-	concatEntries := concatRegex.FindAllStringSubmatch(input, -1)
-
-	if *diceSide == 0 { // only replace #1 with dice_num
-		for _, extracted := range concatEntries {
-			input = strings.ReplaceAll(input, extracted[0], "")
-		}
-	} else {
-		for _, extracted := range concatEntries {
-			input = strings.ReplaceAll(input, extracted[0], fmt.Sprintf(" %s", extracted[1]))
-		}
-	}
-
-	num1Regex := regexp.MustCompile("([-,+]?)#1")
-	num1Entries := num1Regex.FindAllStringSubmatch(input, -1)
-	for _, extracted := range num1Entries {
-		var diceNumStr string
-		if diceNumIsSpellId {
-			diceNumStr = (*langs)[lang].Texts[gameData.spells[*diceNum].NameId]
-		} else {
-			diceNumStr = fmt.Sprint(*diceNum)
-		}
-		input = strings.ReplaceAll(input, extracted[0], fmt.Sprintf("%s%s", extracted[1], diceNumStr))
-	}
-
-	if *diceSide == 0 {
-		input = strings.ReplaceAll(input, "#2", "")
-	} else {
-		var diceSideStr string
-		if diceSideIsSpellId {
-			diceSideStr = (*langs)[lang].Texts[gameData.spells[*diceSide].NameId]
-			//del_dice_side = true
-		} else {
-			if sideSigned && lang == "pt" && !ptSideSigned {
-				diceSideStr = fmt.Sprintf("-%d", *diceSide)
-			} else {
-				diceSideStr = fmt.Sprint(*diceSide)
-			}
-		}
-		input = strings.ReplaceAll(input, "#2", diceSideStr)
-	}
-
-	var valueStr string
-	if valueIsSpellId {
-		valueStr = (*langs)[lang].Texts[gameData.spells[*value].NameId]
-		delValue = true
-	} else {
-		valueStr = fmt.Sprint(*value)
-	}
-	if effectNameId == 427090 { // go to <npc> for more info
-		return "", -2
-	}
-	input = strings.ReplaceAll(input, "#3", valueStr)
-
-	if delValue {
-		*diceNum = Min(*diceNum, *diceSide)
-	}
-
-	if !useDice {
-		// avoid min = 0, max > x
-		if *diceNum == 0 && *diceSide != 0 {
-			*diceNum = *diceSide
-			*diceSide = 0
-		}
-	}
-
-	if *diceNum == 0 && *diceSide == 0 {
-		onlyNoMinMax = -2
-	}
-
-	if *diceNum != 0 && *diceSide == 0 {
-		onlyNoMinMax = -1
-	}
-
-	input = strings.TrimSpace(input)
-
-	if numSigned {
-		*diceNum *= -1
-	}
-
-	if sideSigned {
-		*diceSide *= -1
-	}
-
-	if *diceNum < 0 && *diceSide < 0 {
-		*diceNum, *diceSide = *diceSide, *diceNum
-
-		diceSideFmt := fmt.Sprint((*diceSide) * -1)
-		diceNumFmt := fmt.Sprint((*diceNum) * -1)
-
-		input = strings.ReplaceAll(input, diceSideFmt, "-diceSideFmt-")
-		input = strings.ReplaceAll(input, diceNumFmt, diceSideFmt)
-		input = strings.ReplaceAll(input, "-diceSideFmt-", diceNumFmt)
-
-		if !strings.Contains(input, "-"+diceSideFmt) {
-			input = strings.ReplaceAll(input, diceSideFmt, "-"+diceSideFmt)
-		}
-
-		if !strings.Contains(input, "-"+diceNumFmt) {
-			input = strings.ReplaceAll(input, diceNumFmt, "-"+diceNumFmt)
-		}
-	}
-
-	return input, onlyNoMinMax
+	return numSpellFormatter(input, lang, NewGameDataUnity(gameData, *langs), diceNum, diceSide, value, effectNameId, numIsSpell, useDice, frNumSigned, frSideSigned)
 }
 
 func ParseSignessUnity(input string) (bool, bool) {